@@ -0,0 +1,84 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ikawaha/kagome/tokenizer"
+)
+
+const maxUserDicBytes = 32 << 20
+
+// userDicHandler replaces the user dictionary of the Summarizer with the
+// dictionary in the request body, in the kagome user dictionary format.
+// It requires "Authorization: Bearer {ADMIN_TOKEN}", and is disabled
+// when ADMIN_TOKEN is not set, answering as any unknown path.
+func (s *server) userDicHandler(w http.ResponseWriter, r *http.Request) {
+	if s.adminToken == "" {
+		notFoundHandler(w, r)
+		return
+	}
+	w.Header().Set(requestIDHeader, requestID(r))
+	if !s.authorized(r) {
+		writeError(w, &apiError{
+			status:  http.StatusUnauthorized,
+			code:    codeUnauthorized,
+			message: "admin token is required",
+		})
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		r.Body = http.MaxBytesReader(w, r.Body, maxUserDicBytes)
+		d, err := readUserDic(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		s.summarizer.SetUserDic(d)
+	case http.MethodDelete:
+		s.summarizer.SetUserDic(tokenizer.UserDic{})
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func readUserDic(r *http.Request) (tokenizer.UserDic, error) {
+	records, err := tokenizer.NewUserDicRecords(r.Body)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return tokenizer.UserDic{}, err
+	}
+	if err == nil {
+		var d tokenizer.UserDic
+		d, err = records.NewUserDic()
+		if err == nil {
+			return d, nil
+		}
+	}
+	return tokenizer.UserDic{}, &apiError{
+		status:  http.StatusUnprocessableEntity,
+		code:    codeInvalidValue,
+		message: "invalid user dictionary: " + err.Error(),
+	}
+}
+
+func (s *server) authorized(r *http.Request) bool {
+	const prefix = "Bearer "
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, prefix) {
+		return false
+	}
+	token := strings.TrimPrefix(auth, prefix)
+	return subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) == 1
+}
+
+// loadUserDic loads the user dictionary at path
+func loadUserDic(path string) (tokenizer.UserDic, error) {
+	d, err := tokenizer.NewUserDic(path)
+	if err != nil {
+		return d, errors.New("cannot load user dictionary " + path + ": " + err.Error())
+	}
+	return d, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminDisabledWithoutToken(t *testing.T) {
+	handler := newServer(nil, config{}).router()
+	for _, path := range []string{"/v1/admin/userdic", "/admin/userdic"} {
+		for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete, http.MethodOptions} {
+			r := httptest.NewRequest(method, path, strings.NewReader("東京スカイツリー,東京スカイツリー,トウキョウスカイツリー,カスタム名詞"))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("%s %s: status = %d, want %d", method, path, w.Code, http.StatusNotFound)
+				continue
+			}
+			if w.Header().Get("Allow") != "" {
+				t.Errorf("%s %s: Allow header %q reveals the endpoint", method, path, w.Header().Get("Allow"))
+			}
+			if got := decodeError(t, w); got.Code != codeNotFound {
+				t.Errorf("%s %s: code = %q, want %q", method, path, got.Code, codeNotFound)
+			}
+		}
+	}
+}
+
+func TestAdminRequiresToken(t *testing.T) {
+	handler := newServer(nil, config{adminToken: "secret"}).router()
+	tests := []struct {
+		method string
+		token  string
+		status int
+	}{
+		{http.MethodGet, "secret", http.StatusMethodNotAllowed},
+		{http.MethodPut, "", http.StatusUnauthorized},
+		{http.MethodDelete, "wrong", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/v1/admin/userdic", nil)
+		if tt.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != tt.status {
+			t.Errorf("%s with token %q: status = %d, want %d", tt.method, tt.token, w.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+// maxBatchBytes limits the body of a batch, maxBodyBytes limits each item
+const maxBatchBytes = 100 << 20
+
+var errNotArray = errors.New("request body must be a JSON array")
+
+// errItemTooLarge is the error of an item larger than maxBodyBytes
+var errItemTooLarge = &apiError{
+	status:  http.StatusRequestEntityTooLarge,
+	code:    codeBodyTooLarge,
+	message: "item is too large",
+}
+
+// batchItem is an item of a batch. Fields not given are taken from the query string.
+type batchItem struct {
+	ID string `json:"id"`
+	summarizeRequest
+}
+
+// batchResult is a line of the response of a batch.
+// Index is the position of the item in the batch, or -1 when the batch could not be read.
+type batchResult struct {
+	Index  int                `json:"index"`
+	ID     string             `json:"id,omitempty"`
+	Result *lexrankmmr.Result `json:"result,omitempty"`
+	Error  *errorBody         `json:"error,omitempty"`
+}
+
+// batchInput is an item read from the body of a batch
+type batchInput struct {
+	index int
+	data  []byte
+	err   error
+}
+
+// batchReader returns the function reading the items of the body of r,
+// a JSON array or NDJSON
+func batchReader(r *http.Request) (func(context.Context, io.Reader, chan<- batchInput), error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/json":
+		return readJSONArray, nil
+	case "application/x-ndjson", "application/ndjson":
+		return readNDJSON, nil
+	}
+	return nil, &apiError{
+		status:  http.StatusUnsupportedMediaType,
+		code:    codeUnsupportedType,
+		message: "request body must be application/json or application/x-ndjson",
+	}
+}
+
+// sendInput sends in to inputs unless ctx is done first. An item larger
+// than maxBodyBytes is sent as errItemTooLarge.
+func sendInput(ctx context.Context, inputs chan<- batchInput, in batchInput) bool {
+	if len(in.data) > maxBodyBytes {
+		in = batchInput{index: in.index, err: errItemTooLarge}
+	}
+	select {
+	case inputs <- in:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func readJSONArray(ctx context.Context, body io.Reader, inputs chan<- batchInput) {
+	decoder := json.NewDecoder(body)
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		if err == nil || err == io.EOF {
+			err = errNotArray
+		}
+		sendInput(ctx, inputs, batchInput{index: -1, err: err})
+		return
+	}
+	for index := 0; decoder.More(); index++ {
+		var data json.RawMessage
+		if err := decoder.Decode(&data); err != nil {
+			sendInput(ctx, inputs, batchInput{index: -1, err: err})
+			return
+		}
+		if !sendInput(ctx, inputs, batchInput{index: index, data: data}) {
+			return
+		}
+	}
+	if _, err := decoder.Token(); err != nil {
+		sendInput(ctx, inputs, batchInput{index: -1, err: err})
+	}
+}
+
+func readNDJSON(ctx context.Context, body io.Reader, inputs chan<- batchInput) {
+	reader := bufio.NewReader(body)
+	for index := 0; ; {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			if !sendInput(ctx, inputs, batchInput{index: index, data: line}) {
+				return
+			}
+			index++
+		}
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			sendInput(ctx, inputs, batchInput{index: -1, err: err})
+			return
+		}
+	}
+}
+
+// batchHandler summarizes many documents in one request. The options in
+// the query string are shared by all items, and each item can override them.
+// Results are streamed back as NDJSON in the order they are done, and an item
+// which fails is reported in its line without failing the others.
+func (s *server) batchHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+	requestID := w.Header().Get(requestIDHeader)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBatchBytes)
+	shared := newSummarizeRequest()
+	if err := shared.decodeValues(r.URL.Query()); err != nil {
+		writeError(w, err)
+		return
+	}
+	// shared options which are invalid, e.g. out of range, would fail every item
+	if _, err := s.options(shared); err != nil {
+		writeError(w, err)
+		return
+	}
+	read, err := batchReader(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// results are written while the body is still read, which HTTP/1.x
+	// servers do not allow by default. HTTP/2 always allows it.
+	if err := http.NewResponseController(w).EnableFullDuplex(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	inputs := make(chan batchInput)
+	go func() {
+		defer close(inputs)
+		read(ctx, r.Body, inputs)
+	}()
+
+	results := make(chan batchResult)
+	var wg sync.WaitGroup
+	for i := 0; i < s.batchConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for in := range inputs {
+				result := s.summarizeItem(ctx, shared, in, requestID)
+				select {
+				case results <- result:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for result := range results {
+		if err := encoder.Encode(result); err != nil {
+			// the client went away, stop the remaining items
+			cancel()
+			continue
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+// summarizeItem summarizes an item of a batch with the options of shared
+// overridden by the item
+func (s *server) summarizeItem(ctx context.Context, shared summarizeRequest, in batchInput, requestID string) batchResult {
+	result := batchResult{Index: in.index}
+	if in.err != nil {
+		result.Error = newErrorBody(in.err, requestID)
+		return result
+	}
+	item := batchItem{summarizeRequest: shared.clone()}
+	if err := decodeJSON(bytes.NewReader(in.data), &item); err != nil {
+		result.Error = newErrorBody(err, requestID)
+		return result
+	}
+	result.ID = item.ID
+	options, err := s.options(item.summarizeRequest)
+	if err != nil {
+		result.Error = newErrorBody(err, requestID)
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	summary, err := s.summarizer.Summarize(ctx, item.Text, options...)
+	if err != nil {
+		result.Error = newErrorBody(err, requestID)
+		return result
+	}
+	result.Result = &summary
+	return result
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestBatchStreamsWhileReading sends a batch over HTTP/1.1, where the results
+// are written before the body has been read
+func TestBatchStreamsWhileReading(t *testing.T) {
+	const items = 2000
+	s := newServer(nil, config{batchConcurrency: 2})
+	ts := httptest.NewServer(s.router())
+	defer ts.Close()
+
+	var body strings.Builder
+	for i := 0; i < items; i++ {
+		// the algorithm fails each item without summarizing it
+		fmt.Fprintf(&body, `{"id": "item-%d", "text": "今日は晴れ。", "algorithm": "unknown"}`+"\n", i)
+	}
+	resp, err := http.Post(ts.URL+"/v1/summarize/batch", "application/x-ndjson", strings.NewReader(body.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.ProtoMajor != 1 {
+		t.Fatalf("protocol is %s, want HTTP/1.x", resp.Proto)
+	}
+
+	seen := make([]bool, items)
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var result batchResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		if result.Index < 0 || result.Index >= items {
+			t.Fatalf("unexpected line %s", scanner.Text())
+		}
+		if result.ID != fmt.Sprintf("item-%d", result.Index) || result.Error == nil || result.Error.Code != codeInvalidValue {
+			t.Errorf("unexpected result of item %d: %s", result.Index, scanner.Text())
+		}
+		seen[result.Index] = true
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("no result for item %d", i)
+		}
+	}
+}
+
+func TestBatchRejectsInvalidSharedOptions(t *testing.T) {
+	handler := newServer(nil, config{batchConcurrency: 2}).router()
+	for _, query := range []string{"lambda=5", "threshold=2", "maxLines=-1", "algorithm=unknown"} {
+		body := `{"text": "今日は晴れ。"}` + "\n"
+		r := httptest.NewRequest(http.MethodPost, "/v1/summarize/batch?"+query, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-ndjson")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want %d: %s", query, w.Code, http.StatusUnprocessableEntity, w.Body)
+			continue
+		}
+		decodeError(t, w)
+	}
+}
@@ -0,0 +1,117 @@
+// Command buildidf builds a background IDF table from a directory of
+// Japanese documents, to be loaded by the server with IDF_FILE.
+//
+//	buildidf -dir ./corpus -out idf.gz
+//
+// Every *.txt file under dir is a document. The documents must be tokenized
+// as the server does, so -dic, -userdic, -base-form, -pos, -default-stopwords
+// and -stopwords should match its settings.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ikawaha/kagome/tokenizer"
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+func main() {
+	dir := flag.String("dir", "", "directory of the documents")
+	out := flag.String("out", "idf.gz", "output file")
+	ext := flag.String("ext", ".txt", "extension of the documents")
+	minDF := flag.Int("min-df", 2, "drop terms which occur in less documents")
+	dic := flag.String("dic", string(lexrankmmr.IPA), "system dictionary")
+	baseForm := flag.Bool("base-form", false, "index words by their base form")
+	pos := flag.String("pos", "", "space separated parts of speech used as terms")
+	userDic := flag.String("userdic", "", "user dictionary file")
+	defaultStopwords := flag.Bool("default-stopwords", false, "drop the built-in stopwords")
+	stopwords := flag.String("stopwords", "", "file of stopwords, one per line")
+	flag.Parse()
+	if *dir == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	options := []lexrankmmr.Option{
+		lexrankmmr.SystemDic(lexrankmmr.Dictionary(*dic)),
+		lexrankmmr.BaseForm(*baseForm),
+		lexrankmmr.PartsOfSpeech(strings.Fields(*pos)...),
+	}
+	if *userDic != "" {
+		d, err := tokenizer.NewUserDic(*userDic)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, lexrankmmr.UserDic(d))
+	}
+	if *defaultStopwords {
+		options = append(options, lexrankmmr.DefaultStopwords())
+	}
+	if *stopwords != "" {
+		words, err := readWords(*stopwords)
+		if err != nil {
+			log.Fatal(err)
+		}
+		options = append(options, lexrankmmr.Stopwords(words...))
+	}
+	summarizer, err := lexrankmmr.NewSummarizer(options...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	idf := lexrankmmr.NewIDF()
+	err = filepath.Walk(*dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != *ext {
+			return err
+		}
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		terms, err := summarizer.Terms(context.Background(), string(text))
+		if err != nil {
+			return err
+		}
+		idf.AddDocument(terms)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	idf.Prune(*minDF)
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := idf.Write(f); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Wrote %d terms of %d documents to %s", len(idf.DF), idf.Documents, *out)
+}
+
+// readWords reads a file with one word per line
+func readWords(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words, scanner.Err()
+}
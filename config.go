@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ikawaha/kagome/tokenizer"
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+// config is the server configuration read from environment variables
+type config struct {
+	requestTimeout   time.Duration
+	partsOfSpeech    []string
+	defaultStopwords bool
+	stopwordsFile    string
+	baseForm         bool
+	userDic          string
+	adminToken       string
+	// systemDic is the name of a built-in dictionary or the path of a kagome dictionary
+	systemDic string
+	// dictionaries can be selected by requests
+	dictionaries []string
+	idfFile      string
+	idfWeight    float64
+	// batchConcurrency is the number of items of a batch summarized at the same time
+	batchConcurrency int
+	// jobDir is the directory of the jobs, which are kept in memory if it is empty
+	jobDir     string
+	jobWorkers int
+	jobTimeout time.Duration
+	// jobRetention is how long finished jobs are kept
+	jobRetention time.Duration
+}
+
+func loadConfig() (config, error) {
+	c := config{
+		requestTimeout:   defaultRequestTimeout,
+		partsOfSpeech:    strings.Fields(os.Getenv("PARTS_OF_SPEECH")),
+		stopwordsFile:    os.Getenv("STOPWORDS_FILE"),
+		userDic:          os.Getenv("USER_DIC"),
+		adminToken:       os.Getenv("ADMIN_TOKEN"),
+		systemDic:        os.Getenv("SYSTEM_DIC"),
+		dictionaries:     strings.FieldsFunc(os.Getenv("DICTIONARIES"), isComma),
+		idfFile:          os.Getenv("IDF_FILE"),
+		idfWeight:        defaultIDFWeight,
+		batchConcurrency: runtime.NumCPU(),
+		jobDir:           os.Getenv("JOB_DIR"),
+		jobWorkers:       runtime.NumCPU(),
+		jobTimeout:       defaultJobTimeout,
+		jobRetention:     defaultJobRetention,
+	}
+	if c.systemDic == "" {
+		c.systemDic = string(lexrankmmr.IPA)
+	}
+	var err error
+	if v := os.Getenv("REQUEST_TIMEOUT"); v != "" {
+		c.requestTimeout, err = time.ParseDuration(v)
+		if err != nil {
+			return c, err
+		}
+	}
+	if v := os.Getenv("DEFAULT_STOPWORDS"); v != "" {
+		c.defaultStopwords, err = strconv.ParseBool(v)
+		if err != nil {
+			return c, err
+		}
+	}
+	if v := os.Getenv("IDF_WEIGHT"); v != "" {
+		c.idfWeight, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			return c, err
+		}
+	}
+	if v := os.Getenv("BATCH_CONCURRENCY"); v != "" {
+		c.batchConcurrency, err = strconv.Atoi(v)
+		if err != nil {
+			return c, err
+		}
+		if c.batchConcurrency <= 0 {
+			return c, errors.New("BATCH_CONCURRENCY must be positive")
+		}
+	}
+	if v := os.Getenv("JOB_WORKERS"); v != "" {
+		c.jobWorkers, err = strconv.Atoi(v)
+		if err != nil {
+			return c, err
+		}
+		if c.jobWorkers <= 0 {
+			return c, errors.New("JOB_WORKERS must be positive")
+		}
+	}
+	if v := os.Getenv("JOB_TIMEOUT"); v != "" {
+		c.jobTimeout, err = time.ParseDuration(v)
+		if err != nil {
+			return c, err
+		}
+	}
+	if v := os.Getenv("JOB_RETENTION"); v != "" {
+		c.jobRetention, err = time.ParseDuration(v)
+		if err != nil {
+			return c, err
+		}
+		if c.jobRetention <= 0 {
+			return c, errors.New("JOB_RETENTION must be positive")
+		}
+	}
+	if v := os.Getenv("BASE_FORM"); v != "" {
+		c.baseForm, err = strconv.ParseBool(v)
+		if err != nil {
+			return c, err
+		}
+	}
+	return c, c.checkDictionaries()
+}
+
+// checkDictionaries rejects the full and simple variants of the same
+// dictionary, since kagome serves the variant loaded first for both
+func (c config) checkDictionaries() error {
+	variants := map[lexrankmmr.Dictionary]string{}
+	for _, name := range append([]string{c.systemDic}, c.dictionaries...) {
+		if !isDictionary(name) {
+			continue
+		}
+		full := lexrankmmr.Dictionary(name).Full()
+		if other, ok := variants[full]; ok && other != name {
+			return errors.New("SYSTEM_DIC and DICTIONARIES must not contain both " + other + " and " + name)
+		}
+		variants[full] = name
+	}
+	return nil
+}
+
+// jobStore returns the store of the jobs
+func (c config) jobStore() (jobStore, error) {
+	if c.jobDir == "" {
+		return newMemoryStore(), nil
+	}
+	return newDiskStore(c.jobDir)
+}
+
+// summarizerOptions returns the default options of the shared Summarizer
+func (c config) summarizerOptions() ([]lexrankmmr.Option, error) {
+	options := []lexrankmmr.Option{lexrankmmr.BaseForm(c.baseForm)}
+	if isDictionary(c.systemDic) {
+		options = append(options, lexrankmmr.SystemDic(lexrankmmr.Dictionary(c.systemDic)))
+	} else {
+		d, err := tokenizer.NewDic(c.systemDic)
+		if err != nil {
+			return nil, errors.New("cannot load system dictionary " + c.systemDic + ": " + err.Error())
+		}
+		options = append(options, lexrankmmr.Tokenizer(tokenizer.NewWithDic(d)))
+	}
+	if len(c.partsOfSpeech) > 0 {
+		options = append(options, lexrankmmr.PartsOfSpeech(c.partsOfSpeech...))
+	}
+	if c.defaultStopwords {
+		options = append(options, lexrankmmr.DefaultStopwords())
+	}
+	if c.stopwordsFile != "" {
+		words, err := readWords(c.stopwordsFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, lexrankmmr.Stopwords(words...))
+	}
+	if c.idfFile != "" {
+		idf, err := loadIDF(c.idfFile)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, lexrankmmr.BackgroundIDF(idf, c.idfWeight))
+	}
+	if c.userDic != "" {
+		d, err := loadUserDic(c.userDic)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, lexrankmmr.UserDic(d))
+	}
+	return options, nil
+}
+
+// loadIDF loads the background IDF written by cmd/buildidf
+func loadIDF(path string) (*lexrankmmr.IDF, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return lexrankmmr.ReadIDF(f)
+}
+
+// readWords reads a file with one word per line
+func readWords(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var words []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words, scanner.Err()
+}
+
+func isComma(r rune) bool {
+	return r == ','
+}
+
+func isDictionary(name string) bool {
+	for _, d := range lexrankmmr.Dictionaries {
+		if string(d) == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+// Error codes returned in errorBody.Code
+const (
+	codeInvalidJSON      = "invalid_json"
+	codeUnknownField     = "unknown_field"
+	codeInvalidNumber    = "invalid_number"
+	codeInvalidBool      = "invalid_boolean"
+	codeInvalidForm      = "invalid_form"
+	codeBodyTooLarge     = "body_too_large"
+	codeEmptyText        = "empty_text"
+	codeOutOfRange       = "out_of_range"
+	codeInvalidValue     = "invalid_value"
+	codeUnauthorized     = "unauthorized"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeUnsupportedType  = "unsupported_media_type"
+	codeCanceled         = "canceled"
+	codeTimeout          = "timeout"
+	codeInternal         = "internal_error"
+)
+
+// statusClientClosedRequest is used when the client went away before the response
+const statusClientClosedRequest = 499
+
+const requestIDHeader = "X-Request-Id"
+
+// apiError is an error which is written to the client as errorResponse
+type apiError struct {
+	status  int
+	code    string
+	message string
+	field   string
+}
+
+func (e *apiError) Error() string {
+	return e.message
+}
+
+type errorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Field     string `json:"field,omitempty"`
+	RequestID string `json:"requestId"`
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+// invalidValue returns apiError for a field which is not one of the accepted values
+func invalidValue(field, message string) *apiError {
+	return &apiError{
+		status:  http.StatusUnprocessableEntity,
+		code:    codeInvalidValue,
+		message: message,
+		field:   field,
+	}
+}
+
+// fieldError is returned when a form field cannot be parsed
+type fieldError struct {
+	field string
+	// isBool is true when the field is a boolean, otherwise it is a number
+	isBool bool
+	err    error
+}
+
+func (e *fieldError) Error() string {
+	return e.field + ": " + e.err.Error()
+}
+
+func (e *fieldError) Unwrap() error {
+	return e.err
+}
+
+// formError is returned when the form-data of the body cannot be parsed
+type formError struct {
+	err error
+}
+
+func (e *formError) Error() string {
+	return "invalid form: " + e.err.Error()
+}
+
+func (e *formError) Unwrap() error {
+	return e.err
+}
+
+// toAPIError classifies err into apiError
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &apiError{
+			status:  http.StatusRequestEntityTooLarge,
+			code:    codeBodyTooLarge,
+			message: "request body is too large",
+		}
+	}
+
+	var formErr *formError
+	if errors.As(err, &formErr) {
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    codeInvalidForm,
+			message: "request body is not valid form-data",
+		}
+	}
+
+	var canceledErr *lexrankmmr.CanceledError
+	if errors.As(err, &canceledErr) {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return &apiError{
+				status:  http.StatusServiceUnavailable,
+				code:    codeTimeout,
+				message: "summarization took too long",
+			}
+		}
+		return &apiError{
+			status:  statusClientClosedRequest,
+			code:    codeCanceled,
+			message: "request was canceled",
+		}
+	}
+
+	if errors.Is(err, lexrankmmr.ErrEmptyInput) {
+		return &apiError{
+			status:  http.StatusUnprocessableEntity,
+			code:    codeEmptyText,
+			message: "text must not be empty",
+			field:   "text",
+		}
+	}
+
+	var optionErr *lexrankmmr.OptionError
+	if errors.As(err, &optionErr) {
+		message := optionErr.Option + " must be between 0 and 1"
+		switch {
+		case errors.Is(optionErr.Err, lexrankmmr.ErrNegativeValue):
+			message = optionErr.Option + " must not be negative"
+		case errors.Is(optionErr.Err, lexrankmmr.ErrNotPositive):
+			message = optionErr.Option + " must be positive"
+		}
+		return &apiError{
+			status:  http.StatusUnprocessableEntity,
+			code:    codeOutOfRange,
+			message: message,
+			field:   optionErr.Option,
+		}
+	}
+
+	var fieldErr *fieldError
+	if errors.As(err, &fieldErr) {
+		if fieldErr.isBool {
+			return &apiError{
+				status:  http.StatusBadRequest,
+				code:    codeInvalidBool,
+				message: fieldErr.field + " must be true or false",
+				field:   fieldErr.field,
+			}
+		}
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    codeInvalidNumber,
+			message: fieldErr.field + " must be a number",
+			field:   fieldErr.field,
+		}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    codeInvalidJSON,
+			message: typeErr.Field + " must be of type " + typeErr.Type.String(),
+			field:   typeErr.Field,
+		}
+	}
+
+	const unknownFieldPrefix = "json: unknown field "
+	if strings.HasPrefix(err.Error(), unknownFieldPrefix) {
+		field := strings.Trim(strings.TrimPrefix(err.Error(), unknownFieldPrefix), `"`)
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    codeUnknownField,
+			message: "unknown field " + field,
+			field:   field,
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, errMultipleJSONValues) || errors.Is(err, errEmptyBody) || errors.Is(err, errNotArray) {
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    codeInvalidJSON,
+			message: "request body is not a valid JSON object",
+		}
+	}
+
+	return &apiError{
+		status:  http.StatusInternalServerError,
+		code:    codeInternal,
+		message: "failed to summarize the text",
+	}
+}
+
+// newErrorBody returns errorBody of err
+func newErrorBody(err error, requestID string) *errorBody {
+	apiErr := toAPIError(err)
+	return &errorBody{
+		Code:      apiErr.code,
+		Message:   apiErr.message,
+		Field:     apiErr.field,
+		RequestID: requestID,
+	}
+}
+
+// writeError writes err as errorResponse
+func writeError(w http.ResponseWriter, err error) {
+	apiErr := toAPIError(err)
+	data, _ := json.Marshal(errorResponse{
+		Error: *newErrorBody(err, w.Header().Get(requestIDHeader)),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.status)
+	w.Write(data)
+}
+
+// requestID returns the request id given by the client, or a new one
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(requestIDHeader); id != "" {
+		return id
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
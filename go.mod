@@ -0,0 +1,5 @@
+module github.com/ramenjuniti/summary-generator-api
+
+go 1.22
+
+require github.com/ikawaha/kagome v1.8.1
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
-	"github.com/ramenjuniti/lexrankmmr"
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
 )
 
 const (
@@ -15,87 +17,151 @@ const (
 	defaultThreshold     = 0.1
 	defaultTolerance     = 0.0001
 	defaultDamping       = 0.85
-	defaultLambda        = 1.0
+	defaultLambda        = 0.7
+	defaultRedundancy    = 1.0
+	defaultDuplicate     = 0.9
+	defaultMaxIterations = 1000
+	defaultAlgorithm     = "lexrank"
+	defaultMode          = "discrete"
+	defaultSelection     = "knapsack"
+	defaultVector        = "vocabulary"
+
+	defaultRequestTimeout = 60 * time.Second
+	defaultJobTimeout     = 30 * time.Minute
+	defaultJobRetention   = 24 * time.Hour
+	defaultIDFWeight      = 0.5
 )
 
-func handler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+// server handles requests with a Summarizer shared by all requests
+type server struct {
+	summarizer *lexrankmmr.Summarizer
+	timeout    time.Duration
+	adminToken string
+	// dictionaries can be selected by requests
+	dictionaries     map[string]bool
+	batchConcurrency int
+	jobs             *jobManager
+}
+
+func newServer(summarizer *lexrankmmr.Summarizer, c config) *server {
+	s := &server{
+		summarizer:       summarizer,
+		timeout:          c.requestTimeout,
+		adminToken:       c.adminToken,
+		dictionaries:     map[string]bool{},
+		batchConcurrency: c.batchConcurrency,
+	}
+	for _, d := range append(c.dictionaries, c.systemDic) {
+		if isDictionary(d) {
+			s.dictionaries[d] = true
+		}
+	}
+	return s
+}
+
+// summarizeHandler summarizes the text of the request
+func (s *server) summarizeHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	req, err := parseRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	options, err := s.options(req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	summary, err := s.summarizer.Summarize(ctx, req.Text, options...)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
+	writeJSON(w, summary)
+}
+
+// setHeaders sets the CORS, content type and request id headers of the response
+func setHeaders(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 	w.Header().Set("Access-Control-Allow-Headers", "Accept, Cache-Control, Content-Type")
 	w.Header().Set("Access-Control-Allow-Methods", "POST")
 	w.Header().Set("Content-Type", "application/json")
 
-	text := r.FormValue("text")
-
-	maxLines := defaultMaxLines
-	maxCharacters := defaultMaxCharacters
-	threshold := defaultThreshold
-	tolerance := defaultTolerance
-	damping := defaultDamping
-	lambda := defaultLambda
-	var err error
+	w.Header().Set(requestIDHeader, requestID(r))
+}
 
-	if r.FormValue("maxLines") != "" {
-		maxLines, err = strconv.Atoi(r.FormValue("maxLines"))
-		if err != nil {
-			http.Error(w, err.Error(), 400)
-			return
-		}
+// options returns the options of lexrankmmr given in req, or the error of
+// the first invalid one
+func (s *server) options(req summarizeRequest) ([]lexrankmmr.Option, error) {
+	vectorModel, err := req.vectorModel()
+	if err != nil {
+		return nil, err
 	}
-	if r.FormValue("maxCharacters") != "" {
-		maxCharacters, err = strconv.Atoi(r.FormValue("maxCharacters"))
-		if err != nil {
-			http.Error(w, err.Error(), 400)
-			return
-		}
+	mode, err := req.mode()
+	if err != nil {
+		return nil, err
 	}
-	if r.FormValue("threshold") != "" {
-		threshold, err = strconv.ParseFloat(r.FormValue("threshold"), 64)
-		if err != nil {
-			http.Error(w, err.Error(), 400)
-			return
-		}
+	algorithm, err := req.algorithm()
+	if err != nil {
+		return nil, err
 	}
-	if r.FormValue("tolerance") != "" {
-		tolerance, err = strconv.ParseFloat(r.FormValue("tolerance"), 64)
-		if err != nil {
-			http.Error(w, err.Error(), 400)
-			return
-		}
+	selection, err := req.selection()
+	if err != nil {
+		return nil, err
 	}
-	if r.FormValue("damping") != "" {
-		damping, err = strconv.ParseFloat(r.FormValue("damping"), 64)
-		if err != nil {
-			http.Error(w, err.Error(), 400)
-			return
-		}
+	filters, err := s.filterOptions(req.filterRequest)
+	if err != nil {
+		return nil, err
 	}
-	if r.FormValue("lambda") != "" {
-		lambda, err = strconv.ParseFloat(r.FormValue("lambda"), 64)
-		if err != nil {
-			http.Error(w, err.Error(), 400)
-			return
-		}
+
+	options := append([]lexrankmmr.Option{
+		lexrankmmr.MaxLines(req.MaxLines),
+		lexrankmmr.MaxCharacters(req.MaxCharacters),
+		lexrankmmr.MaxBytes(req.MaxBytes),
+		lexrankmmr.MaxTokens(req.MaxTokens),
+		lexrankmmr.MaxRatio(req.MaxRatio),
+		lexrankmmr.Threshold(req.Threshold),
+		lexrankmmr.Tolerance(req.Tolerance),
+		lexrankmmr.Damping(req.Damping),
+		lexrankmmr.Lambda(req.Lambda),
+		lexrankmmr.RedundancyThreshold(req.RedundancyThreshold),
+		lexrankmmr.MaxIterations(req.MaxIterations),
+		lexrankmmr.LexRankMode(mode),
+		lexrankmmr.Vector(vectorModel),
+		lexrankmmr.UseAlgorithm(algorithm),
+		lexrankmmr.CharacterSelection(selection),
+		lexrankmmr.Query(req.Query),
+	}, filters...)
+	// check the ranges as well, so that jobs and batches are rejected up front
+	if err := lexrankmmr.Validate(options...); err != nil {
+		return nil, err
 	}
+	return options, nil
+}
 
-	summary, err := lexrankmmr.New(
-		lexrankmmr.MaxLines(maxLines),
-		lexrankmmr.MaxCharacters(maxCharacters),
-		lexrankmmr.Threshold(threshold),
-		lexrankmmr.Tolerance(tolerance),
-		lexrankmmr.Damping(damping),
-		lexrankmmr.Lambda(lambda),
-	)
-	err = summary.Summarize(text)
-	if err != nil {
-		http.Error(w, err.Error(), 400)
+// filterOptions returns the options of lexrankmmr for the tokenization given in req
+func (s *server) filterOptions(req filterRequest) ([]lexrankmmr.Option, error) {
+	options := req.filterOptions()
+	if req.Dictionary != "" {
+		if !s.dictionaries[req.Dictionary] {
+			return nil, invalidValue("dictionary", "dictionary "+strconv.Quote(req.Dictionary)+" is not available")
+		}
+		options = append(options, lexrankmmr.SystemDic(lexrankmmr.Dictionary(req.Dictionary)))
 	}
-	data, err := json.Marshal(summary)
+	return options, nil
+}
+
+// writeJSON writes v as the response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	data, err := json.Marshal(v)
 	if err != nil {
-		http.Error(w, err.Error(), 400)
+		writeError(w, err)
+		return
 	}
 	fmt.Fprint(w, string(data))
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// decodeError decodes the errorResponse of w and checks its request id
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) errorBody {
+	t.Helper()
+	if got := w.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var resp errorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("response %q is not an errorResponse: %v", w.Body.String(), err)
+	}
+	if resp.Error.RequestID == "" || resp.Error.RequestID != w.Header().Get(requestIDHeader) {
+		t.Errorf("requestId = %q, want the %s header %q", resp.Error.RequestID, requestIDHeader, w.Header().Get(requestIDHeader))
+	}
+	return resp.Error
+}
+
+func TestInvalidJSONBody(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		code  string
+		field string
+	}{
+		{"empty", "", codeInvalidJSON, ""},
+		{"syntax error", `{"text": "今日は晴れ。"`, codeInvalidJSON, ""},
+		{"broken token", `{"text": 今日}`, codeInvalidJSON, ""},
+		{"trailing value", `{"text": "今日は晴れ。"} {}`, codeInvalidJSON, ""},
+		{"unknown field", `{"text": "今日は晴れ。", "lines": 3}`, codeUnknownField, "lines"},
+		{"wrong type", `{"text": 3}`, codeInvalidJSON, "text"},
+	}
+	handler := newServer(nil, config{}).router()
+	for _, path := range []string{"/v1/summarize", "/v1/keywords", "/v1/summarize/multi", "/"} {
+		for _, tt := range tests {
+			t.Run(path+"/"+tt.name, func(t *testing.T) {
+				r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(tt.body))
+				r.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, r)
+				if w.Code != http.StatusBadRequest {
+					t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusBadRequest, w.Body)
+				}
+				got := decodeError(t, w)
+				if got.Code != tt.code || got.Field != tt.field {
+					t.Errorf("error = %+v, want code %q and field %q", got, tt.code, tt.field)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package lexrankmmr
+
+import (
+	"context"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxKnapsackCells bounds len(sentences) * (capacity+1) of the exact
+// knapsack. Its choice table takes a bit per cell, so this is 8MB, and it
+// takes a few hundred milliseconds. Larger inputs use greedyKnapsack.
+const maxKnapsackCells = 1 << 26
+
+// Selection selects how the summaries limited by a budget are chosen
+type Selection int
+
+const (
+	// Knapsack chooses the sentences with the highest total score within the budget
+	Knapsack Selection = iota
+	// BudgetedMMR greedily chooses the sentences with the highest MMR per unit of the budget
+	BudgetedMMR
+)
+
+// CharacterSelection set config.selection
+func CharacterSelection(selection Selection) Option {
+	return func(args *config) error {
+		if selection != Knapsack && selection != BudgetedMMR {
+			return &OptionError{Option: "selection", Err: ErrOutOfRange}
+		}
+		args.selection = selection
+		return nil
+	}
+}
+
+func (s *SummaryData) createCharacterLimitedSummary(ctx context.Context) error {
+	var err error
+	s.CharacterLimitedSummary, err = s.selectWithin(ctx, s.maxCharacters, s.characterCounts(), s.characters)
+	return err
+}
+
+func (s *SummaryData) createByteLimitedSummary(ctx context.Context) error {
+	byteCounts := make([]int, len(s.originalSentences))
+	for i, sentence := range s.originalSentences {
+		byteCounts[i] = len(sentence)
+	}
+	var err error
+	s.ByteLimitedSummary, err = s.selectWithin(ctx, s.maxBytes, byteCounts, s.bytes)
+	return err
+}
+
+func (s *SummaryData) createTokenLimitedSummary(ctx context.Context) error {
+	var tokens int
+	for _, count := range s.tokenCounts {
+		tokens += count
+	}
+	var err error
+	s.TokenLimitedSummary, err = s.selectWithin(ctx, s.maxTokens, s.tokenCounts, tokens)
+	return err
+}
+
+func (s *SummaryData) createRatioLimitedSummary(ctx context.Context) error {
+	capacity := int(s.maxRatio * float64(s.characters))
+	var err error
+	s.RatioLimitedSummary, err = s.selectWithin(ctx, capacity, s.characterCounts(), s.characters)
+	return err
+}
+
+// characterCounts returns the number of characters of each sentence
+func (s *SummaryData) characterCounts() []int {
+	counts := make([]int, len(s.originalSentences))
+	for i, sentence := range s.originalSentences {
+		counts[i] = utf8.RuneCountInString(sentence)
+	}
+	return counts
+}
+
+// selectWithin chooses sentences whose total weight is at most capacity by
+// config.selection. weight is indexed by sentence id and total is the weight
+// of the whole text. The summary is sorted by sentence id. Knapsack chooses
+// among the sentences kept by calculateMmr, so that it drops the sentences
+// above redundancyThreshold too.
+func (s *SummaryData) selectWithin(ctx context.Context, capacity int, weight []int, total int) ([]ScoredSentence, error) {
+	var summary []ScoredSentence
+	var err error
+	switch {
+	case capacity >= total:
+		summary = append([]ScoredSentence{}, s.reRanking...)
+	case s.selection == BudgetedMMR:
+		summary, err = s.budgetedMmr(ctx, capacity, weight)
+	case len(s.scores)*(capacity+1) > maxKnapsackCells:
+		summary, err = s.greedyKnapsack(ctx, capacity, weight)
+	default:
+		summary, err = s.knapsack(ctx, capacity, weight)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		summary = []ScoredSentence{}
+	}
+	sort.Slice(summary, func(i, j int) bool {
+		return summary[i].Id < summary[j].Id
+	})
+	return summary, nil
+}
+
+// knapsack chooses the sentences of s.reRanking with the highest total score
+// within capacity. It keeps a single row of values and a bit per cell to
+// reconstruct the choice.
+func (s *SummaryData) knapsack(ctx context.Context, capacity int, weight []int) ([]ScoredSentence, error) {
+	n := len(s.reRanking)
+	dp := make([]float64, capacity+1)
+	words := capacity/64 + 1
+	use := make([]uint64, n*words)
+	for i, v := range s.reRanking {
+		if err := checkContext(ctx); err != nil {
+			return nil, err
+		}
+		row := use[i*words : (i+1)*words]
+		for j := capacity; j >= weight[v.Id]; j-- {
+			if value := dp[j-weight[v.Id]] + v.Score; value > dp[j] {
+				dp[j] = value
+				row[j/64] |= 1 << uint(j%64)
+			}
+		}
+	}
+	var selected []ScoredSentence
+	j := capacity
+	for i := n - 1; i >= 0; i-- {
+		if use[i*words+j/64]&(1<<uint(j%64)) != 0 {
+			selected = append(selected, s.reRanking[i])
+			j -= weight[s.reRanking[i].Id]
+		}
+	}
+	return selected, nil
+}
+
+// greedyKnapsack adds the sentences of s.reRanking in order of score per unit
+// of weight while they fit in capacity. The result is replaced by the best
+// single sentence when that is better, so its total score is at least half of
+// the optimum. It is used when the exact knapsack would take too much memory.
+func (s *SummaryData) greedyKnapsack(ctx context.Context, capacity int, weight []int) ([]ScoredSentence, error) {
+	order := make([]ScoredSentence, len(s.reRanking))
+	copy(order, s.reRanking)
+	sort.SliceStable(order, func(a, b int) bool {
+		return order[a].Score*float64(weight[order[b].Id]) > order[b].Score*float64(weight[order[a].Id])
+	})
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+	budget := capacity
+	var selected []ScoredSentence
+	var total float64
+	single := -1
+	for i, v := range order {
+		if weight[v.Id] > capacity {
+			continue
+		}
+		if single < 0 || v.Score > order[single].Score {
+			single = i
+		}
+		if weight[v.Id] <= budget {
+			selected = append(selected, v)
+			budget -= weight[v.Id]
+			total += v.Score
+		}
+	}
+	if single >= 0 && order[single].Score > total {
+		selected = []ScoredSentence{order[single]}
+	}
+	return selected, nil
+}
+
+// budgetedMmr adds the sentence with the highest gain per unit of weight which
+// still fits in capacity until no sentence with a positive gain fits. The gain
+// is MMR shifted by 1-lambda into [0, 1], so that sentences are still chosen
+// when lambda is low and every MMR is negative.
+// Sentences above redundancyThreshold are skipped as in calculateMmr.
+// The result is replaced by the most relevant single sentence when that is
+// better, which keeps the greedy selection within a constant factor of the
+// optimum.
+func (s *SummaryData) budgetedMmr(ctx context.Context, capacity int, weight []int) ([]ScoredSentence, error) {
+	n := len(s.scores)
+	if n == 0 {
+		return nil, nil
+	}
+	relevance := s.relevance()
+	index := s.scoreIndex()
+	shift := 1 - s.lambda
+
+	done := make([]bool, n)
+	maxSim := make([]float64, n)
+	budget := capacity
+	var selected []ScoredSentence
+	var total float64
+	for {
+		if err := checkContext(ctx); err != nil {
+			return nil, err
+		}
+		best, bestGain, bestMmr := -1, 0.0, 0.0
+		for i, candidate := range s.scores {
+			w := weight[candidate.Id]
+			if done[i] || w > budget {
+				continue
+			}
+			mmr := s.lambda*relevance[i] - (1-s.lambda)*maxSim[i]
+			if mmr+shift <= 0 || w == 0 {
+				continue
+			}
+			if gain := (mmr + shift) / float64(w); gain > bestGain {
+				best, bestGain, bestMmr = i, gain, mmr
+			}
+		}
+		if best < 0 {
+			break
+		}
+		done[best] = true
+		budget -= weight[s.scores[best].Id]
+		total += bestMmr + shift
+		sentence := s.scores[best]
+		sentence.Mmr = bestMmr
+		selected = append(selected, sentence)
+		row := s.similarity[sentence.Id]
+		for k, j := range row.Sentences {
+			i := index[j]
+			if i < 0 || done[i] {
+				continue
+			}
+			sim := row.Similarities[k]
+			if sim > maxSim[i] {
+				maxSim[i] = sim
+			}
+			if sim > s.redundancyThreshold {
+				done[i] = true
+			}
+		}
+	}
+
+	single := -1
+	for i, v := range s.scores {
+		if weight[v.Id] <= capacity && (single < 0 || relevance[i] > relevance[single]) {
+			single = i
+		}
+	}
+	if single >= 0 && s.lambda*relevance[single]+shift > total {
+		sentence := s.scores[single]
+		sentence.Mmr = s.lambda * relevance[single]
+		selected = []ScoredSentence{sentence}
+	}
+	return selected, nil
+}
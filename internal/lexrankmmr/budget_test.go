@@ -0,0 +1,216 @@
+package lexrankmmr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+var budgetSizes = []struct {
+	sentences     int
+	maxCharacters int
+}{
+	{100, 1000},
+	{500, 5000},
+	{2000, 20000},
+	{10000, 50000},
+}
+
+// budgetData returns SummaryData with n scored sentences of 10 to 90 characters drawn from r
+func budgetData(r *rand.Rand, n, maxCharacters int) *SummaryData {
+	s := &SummaryData{config: defaultConfig()}
+	s.maxCharacters = maxCharacters
+	s.originalSentences = make([]string, n)
+	s.scores = make([]ScoredSentence, n)
+	for i := range s.scores {
+		characters := 10 + r.Intn(80)
+		s.originalSentences[i] = strings.Repeat("あ", characters)
+		s.scores[i] = ScoredSentence{
+			Id:       i,
+			Sentence: s.originalSentences[i],
+			Score:    r.Float64() / float64(n),
+		}
+		s.characters += characters
+	}
+	// no sentence is redundant
+	s.reRanking = s.scores
+	return s
+}
+
+// bestTotal returns the highest total score of the subsets of s.scores within
+// capacity by trying all of them
+func bestTotal(s *SummaryData, capacity int, weight []int) float64 {
+	var best float64
+	for subset := 0; subset < 1<<uint(len(s.scores)); subset++ {
+		var total float64
+		used := 0
+		for i, v := range s.scores {
+			if subset&(1<<uint(i)) != 0 {
+				total += v.Score
+				used += weight[v.Id]
+			}
+		}
+		if used <= capacity && total > best {
+			best = total
+		}
+	}
+	return best
+}
+
+// checkSelection returns the total score of selected, and fails t if it exceeds capacity
+func checkSelection(t *testing.T, selected []ScoredSentence, capacity int, weight []int) float64 {
+	t.Helper()
+	var total float64
+	used := 0
+	for _, v := range selected {
+		total += v.Score
+		used += weight[v.Id]
+	}
+	if used > capacity {
+		t.Fatalf("%d characters are selected, want %d at most", used, capacity)
+	}
+	return total
+}
+
+func TestKnapsack(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for trial := 0; trial < 300; trial++ {
+		s := budgetData(r, 1+r.Intn(14), 0)
+		weight := s.characterCounts()
+		capacity := r.Intn(s.characters + 1)
+
+		want := bestTotal(s, capacity, weight)
+		selected, err := s.knapsack(context.Background(), capacity, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := checkSelection(t, selected, capacity, weight); math.Abs(got-want) > 1e-12 {
+			t.Fatalf("knapsack of %d sentences within %d: total score %v, want %v", len(s.scores), capacity, got, want)
+		}
+
+		selected, err = s.greedyKnapsack(context.Background(), capacity, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := checkSelection(t, selected, capacity, weight); got < want/2-1e-12 {
+			t.Fatalf("greedyKnapsack of %d sentences within %d: total score %v, want half of %v at least", len(s.scores), capacity, got, want)
+		}
+	}
+}
+
+// rankedData returns budgetData sorted by score as after ranking, with no
+// similarity between its sentences
+func rankedData(n, maxCharacters int) *SummaryData {
+	s := budgetData(rand.New(rand.NewSource(1)), n, maxCharacters)
+	sort.Slice(s.scores, func(i, j int) bool {
+		return s.scores[i].Score > s.scores[j].Score
+	})
+	s.similarity = make(SimilarityGraph, n)
+	return s
+}
+
+func TestBudgetedMmrLowLambda(t *testing.T) {
+	const capacity = 500
+	for _, lambda := range []float64{0, 0.1, 0.3, 0.7, 1} {
+		s := rankedData(50, capacity)
+		s.lambda = lambda
+		weight := s.characterCounts()
+		selected, err := s.budgetedMmr(context.Background(), capacity, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		used := 0
+		chosen := map[int]bool{}
+		for _, v := range selected {
+			used += weight[v.Id]
+			chosen[v.Id] = true
+		}
+		if used > capacity {
+			t.Fatalf("lambda %v: %d characters are selected, want %d at most", lambda, used, capacity)
+		}
+		for _, v := range s.scores {
+			if !chosen[v.Id] && weight[v.Id] <= capacity-used {
+				t.Errorf("lambda %v: sentence %d of %d characters fits in the remaining %d", lambda, v.Id, weight[v.Id], capacity-used)
+				break
+			}
+		}
+	}
+}
+
+func TestKnapsackRedundancy(t *testing.T) {
+	words := [][]string{{"猫", "犬"}, {"猫", "犬"}, {"車", "電車"}, {"空", "海"}}
+	s := tokenizedData(t, words, nil, RedundancyThreshold(0.5))
+	if err := s.rank(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.calculateMmr(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	weight := s.characterCounts()
+	// every sentence but one fits
+	capacity := -weight[0]
+	for _, w := range weight {
+		capacity += w
+	}
+	for name, selection := range map[string]selectFunc{
+		"knapsack":       (*SummaryData).knapsack,
+		"greedyKnapsack": (*SummaryData).greedyKnapsack,
+		"selectWithin": func(s *SummaryData, ctx context.Context, capacity int, weight []int) ([]ScoredSentence, error) {
+			return s.selectWithin(ctx, capacity, weight, capacity+weight[0])
+		},
+	} {
+		selected, err := selection(s, context.Background(), capacity, weight)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chosen := map[int]bool{}
+		for _, v := range selected {
+			chosen[v.Id] = true
+			if v.Mmr <= 0 {
+				t.Errorf("%s: Mmr of sentence %d = %v, want the MMR at its selection", name, v.Id, v.Mmr)
+			}
+		}
+		if chosen[0] && chosen[1] {
+			t.Errorf("%s selected both equal sentences above the redundancy threshold", name)
+		}
+		if len(selected) != 3 {
+			t.Errorf("%s selected %d sentences, want the 3 which are not redundant", name, len(selected))
+		}
+	}
+}
+
+type selectFunc func(*SummaryData, context.Context, int, []int) ([]ScoredSentence, error)
+
+func benchmarkSelection(b *testing.B, selection selectFunc) {
+	for _, size := range budgetSizes {
+		b.Run(fmt.Sprintf("sentences=%d/chars=%d", size.sentences, size.maxCharacters), func(b *testing.B) {
+			s := budgetData(rand.New(rand.NewSource(1)), size.sentences, size.maxCharacters)
+			weight := s.characterCounts()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := selection(s, context.Background(), size.maxCharacters, weight); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkKnapsack(b *testing.B) {
+	benchmarkSelection(b, (*SummaryData).knapsack)
+}
+
+func BenchmarkGreedyKnapsack(b *testing.B) {
+	benchmarkSelection(b, (*SummaryData).greedyKnapsack)
+}
+
+func BenchmarkCharacterLimitedSummary(b *testing.B) {
+	benchmarkSelection(b, func(s *SummaryData, ctx context.Context, capacity int, weight []int) ([]ScoredSentence, error) {
+		return s.selectWithin(ctx, capacity, weight, s.characters)
+	})
+}
@@ -0,0 +1,31 @@
+package lexrankmmr
+
+import "context"
+
+// centroidRanker scores each sentence by the cosine similarity of its
+// TF-IDF vector and the centroid of all sentence vectors.
+type centroidRanker struct{}
+
+func (r centroidRanker) Name() string {
+	return string(Centroid)
+}
+
+func (r centroidRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	vocabulary, vectors := doc.vectors()
+	centroid := make([]float64, len(vocabulary))
+	for _, v := range vectors {
+		v.addTo(centroid, 1/float64(len(vectors)))
+	}
+	centroidNorm := norm(centroid)
+	scores := make([]float64, len(vectors))
+	for i, v := range vectors {
+		if err := checkContext(ctx); err != nil {
+			return Ranking{}, err
+		}
+		if v.norm == 0 || centroidNorm == 0 {
+			continue
+		}
+		scores[i] = v.dotDense(centroid) / (v.norm * centroidNorm)
+	}
+	return Ranking{Scores: scores}, nil
+}
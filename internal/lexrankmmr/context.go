@@ -0,0 +1,27 @@
+package lexrankmmr
+
+import "context"
+
+// CanceledError is returned when the context is done before the summary is generated
+type CanceledError struct {
+	Err error
+}
+
+func (e *CanceledError) Error() string {
+	return "summarization canceled: " + e.Err.Error()
+}
+
+// Unwrap returns the error of the context
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// checkContext returns CanceledError if ctx is done
+func checkContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return &CanceledError{Err: ctx.Err()}
+	default:
+		return nil
+	}
+}
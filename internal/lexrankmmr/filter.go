@@ -0,0 +1,93 @@
+package lexrankmmr
+
+import (
+	"strings"
+
+	"github.com/ikawaha/kagome/tokenizer"
+)
+
+// JapaneseStopwords is the built-in list of Japanese stopwords.
+// It is based on the stopword list of SlothLib.
+var JapaneseStopwords = []string{
+	"あそこ", "あっ", "あの", "あのかた", "あの人", "あり", "あります", "ある", "あれ",
+	"い", "いう", "います", "いる", "う", "うち", "え", "お", "および", "おり", "おります",
+	"か", "かつて", "から", "が", "き", "ここ", "こちら", "こと", "この", "これ", "これら",
+	"さ", "さらに", "し", "しかし", "する", "ず", "せ", "せる", "そこ", "そして", "その",
+	"その他", "その後", "それ", "それぞれ", "それで", "た", "ただし", "たち", "ため", "たり",
+	"だ", "だっ", "だれ", "つ", "て", "で", "でき", "できる", "です", "では", "でも", "と",
+	"という", "といった", "とき", "ところ", "として", "とともに", "とも", "と共に", "どこ",
+	"どの", "な", "ない", "なお", "なかっ", "ながら", "なく", "なっ", "など", "なに", "なら",
+	"なり", "なる", "なん", "に", "において", "における", "について", "にて", "によって",
+	"により", "による", "に対して", "に対する", "に関する", "の", "ので", "のみ", "は", "ば",
+	"へ", "ほか", "ほとんど", "ほど", "ます", "また", "または", "まで", "も", "もの", "ものの",
+	"や", "よう", "より", "ら", "られ", "られる", "れ", "れる", "を", "ん", "及び", "特に",
+}
+
+// PartsOfSpeech set config.partsOfSpeech. Only words whose part of speech
+// matches one of pos are used as terms. pos is a prefix of the kagome
+// features joined by ",", e.g. "名詞" or "名詞,固有名詞".
+// With no pos, every word is used.
+func PartsOfSpeech(pos ...string) Option {
+	return func(args *config) error {
+		args.partsOfSpeech = append([]string(nil), pos...)
+		return nil
+	}
+}
+
+// Stopwords adds words to config.stopwords, which are not used as terms
+func Stopwords(words ...string) Option {
+	return func(args *config) error {
+		// copy so that the stopwords of a Summarizer are not shared with a call
+		stopwords := make(map[string]bool, len(args.stopwords)+len(words))
+		for word := range args.stopwords {
+			stopwords[word] = true
+		}
+		for _, word := range words {
+			stopwords[word] = true
+		}
+		args.stopwords = stopwords
+		return nil
+	}
+}
+
+// DefaultStopwords adds JapaneseStopwords to config.stopwords
+func DefaultStopwords() Option {
+	return Stopwords(JapaneseStopwords...)
+}
+
+// BaseForm set config.baseForm. If it is true, words are indexed by their
+// dictionary base form (基本形), so that 書く, 書いた and 書かれる are the same term.
+func BaseForm(baseForm bool) Option {
+	return func(args *config) error {
+		args.baseForm = baseForm
+		return nil
+	}
+}
+
+// term returns the term which token is indexed by.
+// Tokens without a base form, such as unknown words, fall back to the surface.
+func (c *config) term(token tokenizer.Token) string {
+	if !c.baseForm {
+		return token.Surface
+	}
+	features := token.Features()
+	i := c.dictionary.baseFormFeature()
+	if len(features) <= i || features[i] == "*" || features[i] == "" {
+		return token.Surface
+	}
+	return features[i]
+}
+
+// usePartOfSpeech reports whether token is used as a term by its part of speech
+func (c *config) usePartOfSpeech(token tokenizer.Token) bool {
+	if len(c.partsOfSpeech) == 0 {
+		return true
+	}
+	joined := strings.Join(token.Features(), ",")
+	for _, pos := range c.partsOfSpeech {
+		if joined == pos || strings.HasPrefix(joined, pos+",") {
+			return true
+		}
+	}
+	return false
+}
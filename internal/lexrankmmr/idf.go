@@ -0,0 +1,159 @@
+package lexrankmmr
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// idfHeader is the first line of the file written by IDF.Write
+const idfHeader = "lexrankmmr-idf 1"
+
+// IDF is a table of document frequencies trained on a corpus.
+// It is used as the background IDF with BackgroundIDF.
+type IDF struct {
+	// Documents is the number of documents of the corpus
+	Documents int
+	// DF is the number of documents each term occurs in
+	DF map[string]int
+}
+
+// NewIDF return an empty IDF
+func NewIDF() *IDF {
+	return &IDF{DF: map[string]int{}}
+}
+
+// Weight returns the IDF of term, log((N+1)/(df+1))+1.
+// A term not in the corpus has the highest weight.
+func (m *IDF) Weight(term string) float64 {
+	return math.Log(float64(m.Documents+1)/float64(m.DF[term]+1)) + 1
+}
+
+// AddDocument counts the terms of a tokenized document
+func (m *IDF) AddDocument(wordsPerSentence [][]string) {
+	seen := map[string]bool{}
+	for _, words := range wordsPerSentence {
+		for _, word := range words {
+			if !seen[word] {
+				seen[word] = true
+				m.DF[word]++
+			}
+		}
+	}
+	m.Documents++
+}
+
+// Prune removes the terms which occur in less than minDF documents
+func (m *IDF) Prune(minDF int) {
+	for term, df := range m.DF {
+		if df < minDF {
+			delete(m.DF, term)
+		}
+	}
+}
+
+// Write writes m as gzipped lines of "term<TAB>df" sorted by term,
+// after a header line with the number of documents
+func (m *IDF) Write(w io.Writer) error {
+	terms := make([]string, 0, len(m.DF))
+	for term := range m.DF {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	zw := gzip.NewWriter(w)
+	bw := bufio.NewWriter(zw)
+	fmt.Fprintf(bw, "%s\t%d\n", idfHeader, m.Documents)
+	for _, term := range terms {
+		fmt.Fprintf(bw, "%s\t%d\n", term, m.DF[term])
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// ReadIDF reads IDF written by IDF.Write
+func ReadIDF(r io.Reader) (*IDF, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	scanner := bufio.NewScanner(zr)
+	if !scanner.Scan() {
+		return nil, errors.New("idf: missing header")
+	}
+	header := strings.Split(scanner.Text(), "\t")
+	if len(header) != 2 || header[0] != idfHeader {
+		return nil, errors.New("idf: invalid header")
+	}
+	m := NewIDF()
+	if m.Documents, err = strconv.Atoi(header[1]); err != nil {
+		return nil, fmt.Errorf("idf: invalid number of documents: %v", err)
+	}
+	for line := 2; scanner.Scan(); line++ {
+		i := strings.LastIndexByte(scanner.Text(), '\t')
+		if i < 0 {
+			return nil, fmt.Errorf("idf: invalid line %d", line)
+		}
+		df, err := strconv.Atoi(scanner.Text()[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("idf: invalid line %d: %v", line, err)
+		}
+		m.DF[scanner.Text()[:i]] = df
+	}
+	return m, scanner.Err()
+}
+
+// BackgroundIDF set config.backgroundIDF and config.backgroundWeight.
+// The IDF of a term is weight*(IDF in m) + (1-weight)*(IDF in the document).
+func BackgroundIDF(m *IDF, weight float64) Option {
+	return func(args *config) error {
+		if weight < 0 || weight > 1 {
+			return &OptionError{Option: "idfWeight", Err: ErrOutOfRange}
+		}
+		args.backgroundIDF = m
+		args.backgroundWeight = weight
+		return nil
+	}
+}
+
+// idfFunc returns the IDF of word which occurs in df of n sentences
+type idfFunc func(word string, df, n int) float64
+
+// localIDF is log(N/df)+1 over the sentences of the document
+func localIDF(word string, df, n int) float64 {
+	return math.Log(float64(n)/float64(df)) + 1
+}
+
+// idf returns the idfFunc selected by c
+func (c *config) idf() idfFunc {
+	if c.backgroundIDF == nil || c.backgroundWeight == 0 {
+		return localIDF
+	}
+	m, weight := c.backgroundIDF, c.backgroundWeight
+	return func(word string, df, n int) float64 {
+		return weight*m.Weight(word) + (1-weight)*localIDF(word, df, n)
+	}
+}
+
+// Terms returns the terms of each sentence of text, tokenized as Summarize does
+func (s *Summarizer) Terms(ctx context.Context, text string, options ...Option) ([][]string, error) {
+	data, err := s.newData(options)
+	if err != nil {
+		return nil, err
+	}
+	data.sources = []Source{{Text: text}}
+	data.splitText()
+	if err := data.splitSentence(ctx); err != nil {
+		return nil, err
+	}
+	return data.wordsPerSentence, nil
+}
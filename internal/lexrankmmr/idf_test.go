@@ -0,0 +1,68 @@
+package lexrankmmr
+
+import (
+	"bytes"
+	"compress/gzip"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIDFRoundTrip(t *testing.T) {
+	m := NewIDF()
+	m.AddDocument([][]string{{"猫", "犬"}, {"猫"}})
+	m.AddDocument([][]string{{"猫", "鳥"}})
+	m.AddDocument([][]string{{"a\tb", "犬"}})
+
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadIDF(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Documents != 3 || !reflect.DeepEqual(got.DF, m.DF) {
+		t.Errorf("ReadIDF = %+v, want %+v", got, m)
+	}
+	if w := got.Weight("猫"); w != m.Weight("猫") || w >= got.Weight("unknown") {
+		t.Errorf("Weight of a frequent term = %v, want %v below an unknown term", w, m.Weight("猫"))
+	}
+}
+
+// gzipped returns lines compressed as IDF.Write does
+func gzipped(t *testing.T, lines ...string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(strings.Join(lines, "\n") + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadIDFMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data *bytes.Buffer
+		want string
+	}{
+		{"not gzipped", bytes.NewBufferString(idfHeader + "\t1\n"), "gzip"},
+		{"missing header", gzipped(t), "invalid header"},
+		{"invalid header", gzipped(t, "idf 1\t1"), "invalid header"},
+		{"invalid documents", gzipped(t, idfHeader+"\tmany"), "invalid number of documents"},
+		{"missing frequency", gzipped(t, idfHeader+"\t2", "猫\t2", "犬"), "invalid line 3"},
+		{"invalid frequency", gzipped(t, idfHeader+"\t2", "猫\ttwo"), "invalid line 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := ReadIDF(tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadIDF = %+v, %v, want an error with %q", m, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package lexrankmmr
+
+import (
+	"context"
+	"sort"
+)
+
+// Keyword is a term of a document with its TF-IDF weight
+type Keyword struct {
+	Word  string  `json:"word"`
+	Score float64 `json:"score"`
+	Count int     `json:"count"`
+}
+
+// Keywords returns the n terms of text with the highest TF-IDF weight,
+// tokenized and filtered as Summarize does. TF is the count of the term in
+// the text and IDF is that of Summarize over its sentences.
+// n of 0 returns all terms.
+func (s *Summarizer) Keywords(ctx context.Context, text string, n int, options ...Option) ([]Keyword, error) {
+	if n < 0 {
+		return nil, &OptionError{Option: "maxKeywords", Err: ErrNegativeValue}
+	}
+	if len(text) == 0 {
+		return nil, ErrEmptyInput
+	}
+	data, err := s.newData(options)
+	if err != nil {
+		return nil, err
+	}
+	data.sources = []Source{{Text: text}}
+	data.splitText()
+	if err := data.splitSentence(ctx); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	df := map[string]int{}
+	for _, words := range data.wordsPerSentence {
+		seen := map[string]bool{}
+		for _, word := range words {
+			counts[word]++
+			if !seen[word] {
+				seen[word] = true
+				df[word]++
+			}
+		}
+	}
+	idf := data.idf()
+	keywords := make([]Keyword, 0, len(counts))
+	for word, count := range counts {
+		keywords = append(keywords, Keyword{
+			Word:  word,
+			Score: float64(count) * idf(word, df[word], len(data.wordsPerSentence)),
+			Count: count,
+		})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Word < keywords[j].Word
+	})
+	if n > 0 && n < len(keywords) {
+		keywords = keywords[:n]
+	}
+	return keywords, nil
+}
@@ -0,0 +1,80 @@
+package lexrankmmr
+
+import (
+	"context"
+	"math"
+)
+
+// klSmoothing is added to every word count of the summary so that
+// the summary distribution has no zero probability
+const klSmoothing = 0.001
+
+// klSumRanker is KL-Sum of Haghighi and Vanderwende. It greedily adds the
+// sentence which minimizes KL(P||Q), where P is the word distribution of the
+// document and Q is the smoothed word distribution of the summary.
+// Sentences are scored by the order in which they were added.
+//
+// KL(P||Q) = Σ p log p - Σ p log(c+δ) + log(N+δ|V|) for summary counts c and
+// length N, so only the terms of the candidate sentence have to be updated.
+type klSumRanker struct{}
+
+func (r klSumRanker) Name() string {
+	return string(KLSum)
+}
+
+func (r klSumRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	n := len(doc.Words)
+	counts, total := wordCounts(doc.Words)
+	p := make(map[string]float64, len(counts))
+	for word, count := range counts {
+		p[word] = float64(count) / float64(total)
+	}
+	smoothing := klSmoothing * float64(len(counts))
+
+	sentenceCounts := make([]map[string]int, n)
+	for i, words := range doc.Words {
+		sentenceCounts[i] = map[string]int{}
+		for _, word := range words {
+			sentenceCounts[i][word]++
+		}
+	}
+
+	summaryCounts := map[string]int{}
+	summaryLength := 0
+	picked := make([]bool, n)
+	order := make([]int, 0, n)
+	for len(order) < n {
+		if err := checkContext(ctx); err != nil {
+			return Ranking{}, err
+		}
+		best, bestGain := -1, math.Inf(-1)
+		for i, sentence := range sentenceCounts {
+			if picked[i] {
+				continue
+			}
+			if len(sentence) == 0 {
+				// a sentence without words does not change the summary, so it comes last
+				if best < 0 {
+					best = i
+				}
+				continue
+			}
+			// change of Σ p log(c+δ) - log(N+δ|V|), up to a constant
+			gain := -math.Log(float64(summaryLength+len(doc.Words[i])) + smoothing)
+			for word, count := range sentence {
+				c := float64(summaryCounts[word])
+				gain += p[word] * (math.Log(c+float64(count)+klSmoothing) - math.Log(c+klSmoothing))
+			}
+			if gain > bestGain {
+				best, bestGain = i, gain
+			}
+		}
+		picked[best] = true
+		order = append(order, best)
+		for word, count := range sentenceCounts[best] {
+			summaryCounts[word] += count
+		}
+		summaryLength += len(doc.Words[best])
+	}
+	return Ranking{Scores: scoresFromOrder(order, n)}, nil
+}
@@ -0,0 +1,642 @@
+package lexrankmmr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ikawaha/kagome/tokenizer"
+)
+
+// SummaryData contains data for summary
+type SummaryData struct {
+	config
+	tokenizer tokenizer.Tokenizer
+
+	characters        int
+	bytes             int
+	sources           []Source
+	sentences         []Sentence
+	sentenceSources   []int
+	originalSentences []string
+	wordsPerSentence  [][]string
+	tokenCounts       []int
+	queryWords        []string
+	queryRelevance    []float64
+	tfScores          [][]float64
+	idfScores         [][]float64
+	tfIdfScores       [][]float64
+	vocabulary        map[string]int
+	vectors           []sparseVector
+	similarity        SimilarityGraph
+
+	scores    []ScoredSentence
+	reRanking []ScoredSentence
+
+	lastStage   Stage
+	lastPercent float64
+
+	LineLimitedSummary      []ScoredSentence
+	CharacterLimitedSummary []ScoredSentence
+	ByteLimitedSummary      []ScoredSentence
+	TokenLimitedSummary     []ScoredSentence
+	RatioLimitedSummary     []ScoredSentence
+	Algorithm               string
+	PageRank                *PageRankStats
+}
+
+// config contains options for summary
+type config struct {
+	maxLines            int
+	maxCharacters       int
+	maxBytes            int
+	maxTokens           int
+	maxRatio            float64
+	query               string
+	threshold           float64
+	tolerance           float64
+	damping             float64
+	lambda              float64
+	redundancyThreshold float64
+	duplicateThreshold  float64
+	selection           Selection
+	maxIterations       int
+	mode                Mode
+	vectorModel         VectorModel
+	algorithm           Algorithm
+	ranker              Ranker
+	partsOfSpeech       []string
+	stopwords           map[string]bool
+	baseForm            bool
+	dictionary          Dictionary
+	tokenizer           *tokenizer.Tokenizer
+	userDic             *tokenizer.UserDic
+
+	backgroundIDF    *IDF
+	backgroundWeight float64
+
+	progress func(Progress)
+}
+
+// Mode selects how the sentence graph of LexRank is built
+type Mode int
+
+const (
+	// Discrete links sentences whose similarity is at least the threshold with weight 1
+	Discrete Mode = iota
+	// Continuous links sentences weighted by their similarity
+	Continuous
+)
+
+// ScoredSentence is a sentence of the summary with its score and position.
+// With SummarizeDocuments, Source is the ID of the source and the offsets are into its text.
+type ScoredSentence struct {
+	Id        int     `json:"id"`
+	Sentence  string  `json:"sentence"`
+	Score     float64 `json:"score"`
+	Mmr       float64 `json:"mmr"`
+	Source    string  `json:"source,omitempty"`
+	Start     int     `json:"start"`
+	End       int     `json:"end"`
+	StartByte int     `json:"startByte"`
+	EndByte   int     `json:"endByte"`
+}
+
+// Option for Functional Option Pattern
+type Option func(*config) error
+
+var (
+	// ErrEmptyInput is returned when the text to summarize is empty
+	ErrEmptyInput = errors.New("input isn't specifyed")
+	// ErrNegativeValue is returned when an option must not be negative
+	ErrNegativeValue = errors.New("cannot input negative value")
+	// ErrNotPositive is returned when an option must be positive
+	ErrNotPositive = errors.New("cannot input non-positive value")
+	// ErrOutOfRange is returned when an option is out of its range
+	ErrOutOfRange = errors.New("cannot input value out of range")
+)
+
+// OptionError records which option was rejected and why
+type OptionError struct {
+	Option string
+	Err    error
+}
+
+func (e *OptionError) Error() string {
+	return e.Option + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+const (
+	defaultMaxLines      = 0
+	defaultMaxCharacters = 0
+	defaultThreshold     = 0.001
+	defaultTolerance     = 0.0001
+	defaultDamping       = 0.85
+	defaultLambda        = 0.7
+	defaultRedundancy    = 1
+	defaultDuplicate     = 0.9
+	defaultMaxIterations = 1000
+)
+
+// MaxLines set config.maxLines
+func MaxLines(maxLines int) Option {
+	return func(args *config) error {
+		if maxLines < 0 {
+			return &OptionError{Option: "maxLines", Err: ErrNegativeValue}
+		}
+		args.maxLines = maxLines
+		return nil
+	}
+}
+
+// MaxCharacters set config.maxCharacters
+func MaxCharacters(maxCharacters int) Option {
+	return func(args *config) error {
+		if maxCharacters < 0 {
+			return &OptionError{Option: "maxCharacters", Err: ErrNegativeValue}
+		}
+		args.maxCharacters = maxCharacters
+		return nil
+	}
+}
+
+// MaxBytes set config.maxBytes, the limit of ByteLimitedSummary in UTF-8 bytes
+func MaxBytes(maxBytes int) Option {
+	return func(args *config) error {
+		if maxBytes < 0 {
+			return &OptionError{Option: "maxBytes", Err: ErrNegativeValue}
+		}
+		args.maxBytes = maxBytes
+		return nil
+	}
+}
+
+// MaxTokens set config.maxTokens, the limit of TokenLimitedSummary in tokens of the tokenizer
+func MaxTokens(maxTokens int) Option {
+	return func(args *config) error {
+		if maxTokens < 0 {
+			return &OptionError{Option: "maxTokens", Err: ErrNegativeValue}
+		}
+		args.maxTokens = maxTokens
+		return nil
+	}
+}
+
+// MaxRatio set config.maxRatio, the limit of RatioLimitedSummary as a fraction of the characters of the text
+func MaxRatio(maxRatio float64) Option {
+	return func(args *config) error {
+		if maxRatio < 0 || maxRatio > 1 {
+			return &OptionError{Option: "maxRatio", Err: ErrOutOfRange}
+		}
+		args.maxRatio = maxRatio
+		return nil
+	}
+}
+
+// Threshold set config.threshold
+func Threshold(threshold float64) Option {
+	return func(args *config) error {
+		if threshold < 0 || threshold > 1 {
+			return &OptionError{Option: "threshold", Err: ErrOutOfRange}
+		}
+		args.threshold = threshold
+		return nil
+	}
+}
+
+// Tolerance set config.tolerance
+func Tolerance(tolerance float64) Option {
+	return func(args *config) error {
+		if tolerance < 0 || tolerance > 1 {
+			return &OptionError{Option: "tolerance", Err: ErrOutOfRange}
+		}
+		args.tolerance = tolerance
+		return nil
+	}
+}
+
+// Damping set config.damping
+func Damping(damping float64) Option {
+	return func(args *config) error {
+		if damping < 0 || damping > 1 {
+			return &OptionError{Option: "damping", Err: ErrOutOfRange}
+		}
+		args.damping = damping
+		return nil
+	}
+}
+
+// Lambda set config.lambda, the balance of relevance and diversity in MMR.
+// 1 ranks by relevance only, 0 by diversity only. Smaller values make the
+// summary cover more topics.
+func Lambda(lambda float64) Option {
+	return func(args *config) error {
+		if lambda < 0 || lambda > 1 {
+			return &OptionError{Option: "lambda", Err: ErrOutOfRange}
+		}
+		args.lambda = lambda
+		return nil
+	}
+}
+
+// RedundancyThreshold set config.redundancyThreshold. Sentences whose
+// similarity to an already selected sentence is above it are not used in
+// the summary. 1 keeps every sentence.
+func RedundancyThreshold(threshold float64) Option {
+	return func(args *config) error {
+		if threshold < 0 || threshold > 1 {
+			return &OptionError{Option: "redundancyThreshold", Err: ErrOutOfRange}
+		}
+		args.redundancyThreshold = threshold
+		return nil
+	}
+}
+
+// MaxIterations set config.maxIterations, the iteration limit of PageRank
+func MaxIterations(maxIterations int) Option {
+	return func(args *config) error {
+		if maxIterations <= 0 {
+			return &OptionError{Option: "maxIterations", Err: ErrNotPositive}
+		}
+		args.maxIterations = maxIterations
+		return nil
+	}
+}
+
+// LexRankMode set config.mode
+func LexRankMode(mode Mode) Option {
+	return func(args *config) error {
+		if mode != Discrete && mode != Continuous {
+			return &OptionError{Option: "mode", Err: ErrOutOfRange}
+		}
+		args.mode = mode
+		return nil
+	}
+}
+
+// Vector set config.vectorModel
+func Vector(model VectorModel) Option {
+	return func(args *config) error {
+		if model != VocabularyVector && model != PositionalVector {
+			return &OptionError{Option: "vector", Err: ErrOutOfRange}
+		}
+		args.vectorModel = model
+		return nil
+	}
+}
+
+func defaultConfig() config {
+	return config{
+		maxLines:            defaultMaxLines,
+		maxCharacters:       defaultMaxCharacters,
+		threshold:           defaultThreshold,
+		tolerance:           defaultTolerance,
+		damping:             defaultDamping,
+		lambda:              defaultLambda,
+		redundancyThreshold: defaultRedundancy,
+		duplicateThreshold:  defaultDuplicate,
+		maxIterations:       defaultMaxIterations,
+		algorithm:           LexRank,
+		dictionary:          IPA,
+	}
+}
+
+// apply applies options to c in order and stops at the first error
+func (c *config) apply(options []Option) error {
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate returns the error of the first invalid option of options, as
+// Summarize would return it, without summarizing anything
+func Validate(options ...Option) error {
+	c := defaultConfig()
+	return c.apply(options)
+}
+
+// New return SummaryData
+//
+// Deprecated: use NewSummarizer, which can be shared between goroutines.
+func New(options ...Option) (*SummaryData, error) {
+	summaryData := &SummaryData{config: defaultConfig()}
+	err := summaryData.config.apply(options)
+	summaryData.tokenizer = summaryData.newTokenizer()
+	return summaryData, err
+}
+
+// Summarize generate summary
+//
+// Deprecated: use Summarizer.Summarize.
+func (s *SummaryData) Summarize(text string) error {
+	return s.summarize(context.Background(), text)
+}
+
+func (s *SummaryData) summarize(ctx context.Context, text string) error {
+	return s.summarizeSources(ctx, []Source{{Text: text}})
+}
+
+func (s *SummaryData) summarizeSources(ctx context.Context, sources []Source) error {
+	empty := true
+	for _, source := range sources {
+		if len(source.Text) != 0 {
+			empty = false
+		}
+	}
+	if empty {
+		return ErrEmptyInput
+	}
+	s.sources = sources
+	s.report(StageSegment, 0, 0)
+	s.countCharacter()
+	s.splitText()
+	if err := s.splitSentence(ctx); err != nil {
+		return err
+	}
+	s.splitQuery()
+	if s.vectorModel == PositionalVector {
+		if err := s.calculateTf(ctx); err != nil {
+			return err
+		}
+		if err := s.calculateIdf(ctx); err != nil {
+			return err
+		}
+		s.calculateTfidf()
+	} else {
+		s.buildVocabulary()
+		s.calculateVectors()
+	}
+	if err := s.createSimilarityGraph(ctx); err != nil {
+		return err
+	}
+	s.report(StageRank, 0, 0)
+	if err := s.rank(ctx); err != nil {
+		return err
+	}
+	s.report(StageSelect, 0, 0)
+	s.removeDuplicates()
+	if err := s.calculateMmr(ctx); err != nil {
+		return err
+	}
+	s.createLineLimitedSummary()
+	sort.Slice(s.LineLimitedSummary, func(i, j int) bool {
+		return s.LineLimitedSummary[i].Id < s.LineLimitedSummary[j].Id
+	})
+	for _, create := range []func(context.Context) error{
+		s.createCharacterLimitedSummary,
+		s.createByteLimitedSummary,
+		s.createTokenLimitedSummary,
+		s.createRatioLimitedSummary,
+	} {
+		if err := create(ctx); err != nil {
+			return err
+		}
+	}
+	s.report(StageSelect, 1, 1)
+	return nil
+}
+
+func (s *SummaryData) countCharacter() {
+	s.characters, s.bytes = 0, 0
+	for _, source := range s.sources {
+		s.characters += utf8.RuneCountInString(source.Text)
+		s.bytes += len(source.Text)
+	}
+}
+
+func (s *SummaryData) splitText() {
+	s.sentences = []Sentence{}
+	s.sentenceSources = []int{}
+	for i, source := range s.sources {
+		for _, sentence := range Segment(source.Text) {
+			s.sentences = append(s.sentences, sentence)
+			s.sentenceSources = append(s.sentenceSources, i)
+		}
+	}
+	s.originalSentences = make([]string, len(s.sentences))
+	for i, sentence := range s.sentences {
+		s.originalSentences[i] = sentence.Text
+	}
+}
+
+func (s *SummaryData) splitSentence(ctx context.Context) error {
+	s.wordsPerSentence = make([][]string, len(s.originalSentences))
+	s.tokenCounts = make([]int, len(s.originalSentences))
+	for i, sentence := range s.originalSentences {
+		if err := checkContext(ctx); err != nil {
+			return err
+		}
+		s.report(StageTokenize, i, len(s.originalSentences))
+		s.wordsPerSentence[i], s.tokenCounts[i] = s.words(sentence)
+	}
+	return nil
+}
+
+// words returns the terms of text and the number of its tokens
+func (s *SummaryData) words(text string) ([]string, int) {
+	tokens := s.tokenizer.Tokenize(text)[1:]
+	words := make([]string, 0, len(tokens)-1)
+	for j := 0; j < len(tokens)-1; j++ {
+		if strings.TrimFunc(tokens[j].Surface, isTerminatorRune) == "" {
+			// terminal punctuation is kept in the sentence but is not a word
+			continue
+		}
+		if !s.usePartOfSpeech(tokens[j]) {
+			continue
+		}
+		term := s.term(tokens[j])
+		if s.stopwords[term] {
+			continue
+		}
+		words = append(words, term)
+	}
+	return words, len(tokens) - 1
+}
+
+// calculateTf sets the frequency of each word in the whole document
+func (s *SummaryData) calculateTf(ctx context.Context) error {
+	s.tfScores = make([][]float64, len(s.originalSentences))
+	counts := map[string]int{}
+	var allWordsCount float64
+	for _, sentence := range s.wordsPerSentence {
+		for _, word := range sentence {
+			counts[word]++
+		}
+		allWordsCount += float64(len(sentence))
+	}
+	for i, sentence := range s.wordsPerSentence {
+		if err := checkContext(ctx); err != nil {
+			return err
+		}
+		s.tfScores[i] = make([]float64, len(sentence))
+		for j, word := range sentence {
+			s.tfScores[i][j] = float64(counts[word]) / allWordsCount
+		}
+	}
+	return nil
+}
+
+// calculateIdf sets the IDF of each word, as calculateVectors does
+func (s *SummaryData) calculateIdf(ctx context.Context) error {
+	s.idfScores = make([][]float64, len(s.originalSentences))
+	n := len(s.originalSentences)
+	idf := s.idf()
+	df := map[string]int{}
+	for _, sentence := range s.wordsPerSentence {
+		seen := map[string]bool{}
+		for _, word := range sentence {
+			if !seen[word] {
+				seen[word] = true
+				df[word]++
+			}
+		}
+	}
+	for i, sentence := range s.wordsPerSentence {
+		if err := checkContext(ctx); err != nil {
+			return err
+		}
+		s.idfScores[i] = make([]float64, len(sentence))
+		for j, word := range sentence {
+			s.idfScores[i][j] = idf(word, df[word], n)
+		}
+	}
+	return nil
+}
+
+func (s *SummaryData) calculateTfidf() {
+	s.tfIdfScores = make([][]float64, len(s.originalSentences))
+	for i := 0; i < len(s.originalSentences); i++ {
+		s.tfIdfScores[i] = make([]float64, len(s.wordsPerSentence[i]))
+		for j := 0; j < len(s.wordsPerSentence[i]); j++ {
+			s.tfIdfScores[i][j] = s.tfScores[i][j] * s.idfScores[i][j]
+		}
+	}
+}
+
+func (s *SummaryData) rank(ctx context.Context) error {
+	ranker := s.newRanker()
+	doc := &Document{
+		Sentences:  s.sentences,
+		Words:      s.wordsPerSentence,
+		Similarity: s.similarity,
+		Query:      s.queryWords,
+		idf:        s.idf(),
+	}
+	if s.queryWords != nil {
+		doc.Relevance = doc.queryRelevance()
+		s.queryRelevance = doc.Relevance
+	}
+	ranking, err := ranker.Rank(ctx, doc)
+	if err != nil {
+		return err
+	}
+	if len(ranking.Scores) != len(s.sentences) {
+		return fmt.Errorf("%s returned %d scores for %d sentences", ranker.Name(), len(ranking.Scores), len(s.sentences))
+	}
+	s.Algorithm = ranker.Name()
+	s.PageRank = ranking.PageRank
+	s.scores = make([]ScoredSentence, len(s.sentences))
+	for identifier, score := range ranking.Scores {
+		sentence := s.sentences[identifier]
+		s.scores[identifier] = ScoredSentence{
+			Id:        identifier,
+			Sentence:  sentence.Text,
+			Score:     score,
+			Source:    s.sources[s.sentenceSources[identifier]].ID,
+			Start:     sentence.Start,
+			End:       sentence.End,
+			StartByte: sentence.StartByte,
+			EndByte:   sentence.EndByte,
+		}
+	}
+	sort.SliceStable(s.scores, func(i, j int) bool {
+		return s.scores[i].Score > s.scores[j].Score
+	})
+	return nil
+}
+
+// calculateMmr reranks the sentences by Maximal Marginal Relevance,
+// lambda*relevance - (1-lambda)*(max similarity to the selected sentences),
+// where relevance is given by SummaryData.relevance, so that both terms are
+// between 0 and 1. Sentences more similar than redundancyThreshold
+// to a selected sentence are dropped.
+func (s *SummaryData) calculateMmr(ctx context.Context) error {
+	s.reRanking = []ScoredSentence{}
+	if len(s.scores) == 0 {
+		return nil
+	}
+	relevance := s.relevance()
+	index := s.scoreIndex()
+	done := make([]bool, len(s.scores))
+	maxSim := make([]float64, len(s.scores))
+	for remaining := len(s.scores); remaining > 0; {
+		if err := checkContext(ctx); err != nil {
+			return err
+		}
+		best, bestMmr := -1, math.Inf(-1)
+		for i := range s.scores {
+			if done[i] {
+				continue
+			}
+			if mmr := s.lambda*relevance[i] - (1-s.lambda)*maxSim[i]; mmr > bestMmr {
+				best, bestMmr = i, mmr
+			}
+		}
+		done[best] = true
+		remaining--
+		selected := s.scores[best]
+		selected.Mmr = bestMmr
+		s.reRanking = append(s.reRanking, selected)
+		// only the sentences similar to the selected one are affected
+		row := s.similarity[selected.Id]
+		for k, j := range row.Sentences {
+			i := index[j]
+			if i < 0 || done[i] {
+				continue
+			}
+			sim := row.Similarities[k]
+			if sim > maxSim[i] {
+				maxSim[i] = sim
+			}
+			if sim > s.redundancyThreshold {
+				done[i] = true
+				remaining--
+			}
+		}
+	}
+	return nil
+}
+
+// scoreIndex returns the index in s.scores of each sentence, or -1 for the
+// sentences removed from it
+func (s *SummaryData) scoreIndex() []int {
+	index := make([]int, len(s.similarity))
+	for i := range index {
+		index[i] = -1
+	}
+	for i, v := range s.scores {
+		index[v.Id] = i
+	}
+	return index
+}
+
+func (s *SummaryData) createLineLimitedSummary() {
+	s.LineLimitedSummary = []ScoredSentence{}
+	if s.maxLines >= len(s.reRanking) {
+		s.LineLimitedSummary = append(s.LineLimitedSummary, s.reRanking...)
+		return
+	}
+	s.LineLimitedSummary = append(s.LineLimitedSummary, s.reRanking[:s.maxLines]...)
+}
@@ -0,0 +1,121 @@
+package lexrankmmr
+
+import (
+	"context"
+	"math"
+	"math/rand"
+)
+
+const (
+	defaultLSADimensions = 10
+	lsaIterations        = 200
+	lsaEpsilon           = 1e-9
+)
+
+// lsaRanker scores sentences with the SVD of the TF-IDF term-sentence matrix A
+// as Steinberger and Ježek: the score of sentence i is sqrt(Σ σk² vik²) over the
+// top singular values σk and right singular vectors vk.
+// The singular vectors are the eigenvectors of AᵀA found by power iteration.
+type lsaRanker struct {
+	dimensions int
+}
+
+func (r lsaRanker) Name() string {
+	return string(LSA)
+}
+
+func (r lsaRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	vocabulary, vectors := doc.vectors()
+	n := len(vectors)
+	scores := make([]float64, n)
+	dimensions := r.dimensions
+	if dimensions > n {
+		dimensions = n
+	}
+
+	// multiply returns AᵀA v
+	u := make([]float64, len(vocabulary))
+	multiply := func(v []float64) []float64 {
+		for i := range u {
+			u[i] = 0
+		}
+		for i, vector := range vectors {
+			vector.addTo(u, v[i])
+		}
+		w := make([]float64, n)
+		for i, vector := range vectors {
+			w[i] = vector.dotDense(u)
+		}
+		return w
+	}
+
+	random := rand.New(rand.NewSource(1))
+	var eigenvectors [][]float64
+	for k := 0; k < dimensions; k++ {
+		v := make([]float64, n)
+		for i := range v {
+			v[i] = random.Float64()
+		}
+		orthogonalize(v, eigenvectors)
+		if !normalize(v) {
+			break
+		}
+		var eigenvalue float64
+		for iteration := 0; iteration < lsaIterations; iteration++ {
+			if err := checkContext(ctx); err != nil {
+				return Ranking{}, err
+			}
+			w := multiply(v)
+			orthogonalize(w, eigenvectors)
+			eigenvalue = norm(w)
+			if !normalize(w) {
+				break
+			}
+			var change float64
+			for i := range w {
+				change += math.Abs(w[i] - v[i])
+			}
+			v = w
+			if change < lsaEpsilon {
+				break
+			}
+		}
+		if eigenvalue < lsaEpsilon {
+			break
+		}
+		eigenvectors = append(eigenvectors, v)
+		for i := range scores {
+			// σ² = eigenvalue of AᵀA
+			scores[i] += eigenvalue * v[i] * v[i]
+		}
+	}
+	for i := range scores {
+		scores[i] = math.Sqrt(scores[i])
+	}
+	return Ranking{Scores: scores}, nil
+}
+
+// orthogonalize removes the components of v along each of the orthonormal basis
+func orthogonalize(v []float64, basis [][]float64) {
+	for _, b := range basis {
+		var dot float64
+		for i := range v {
+			dot += v[i] * b[i]
+		}
+		for i := range v {
+			v[i] -= dot * b[i]
+		}
+	}
+}
+
+// normalize scales v to unit length, and reports false if v is zero
+func normalize(v []float64) bool {
+	length := norm(v)
+	if length < lsaEpsilon {
+		return false
+	}
+	for i := range v {
+		v[i] /= length
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package lexrankmmr
+
+import (
+	"context"
+	"sort"
+)
+
+const (
+	// luhnSignificantRatio is the ratio of the most frequent words treated as significant
+	luhnSignificantRatio = 0.1
+	// luhnMaxGap is the number of insignificant words allowed inside a cluster
+	luhnMaxGap = 4
+)
+
+// luhnRanker is the method of Luhn. Significant words are the most frequent
+// words of the document. A sentence is scored by its best cluster of
+// significant words, as (significant words)² / (length of the cluster).
+type luhnRanker struct{}
+
+func (r luhnRanker) Name() string {
+	return string(Luhn)
+}
+
+func (r luhnRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	significant := significantWords(doc.Words)
+	scores := make([]float64, len(doc.Words))
+	for i, words := range doc.Words {
+		if err := checkContext(ctx); err != nil {
+			return Ranking{}, err
+		}
+		first, last, count := -1, -1, 0
+		for j, word := range words {
+			if !significant[word] {
+				continue
+			}
+			if last >= 0 && j-last-1 > luhnMaxGap {
+				first, count = -1, 0
+			}
+			if first < 0 {
+				first = j
+			}
+			last = j
+			count++
+			if score := float64(count*count) / float64(last-first+1); score > scores[i] {
+				scores[i] = score
+			}
+		}
+	}
+	return Ranking{Scores: scores}, nil
+}
+
+// significantWords returns the most frequent words which occur more than once.
+// If every word occurs once, every word is significant.
+func significantWords(wordsPerSentence [][]string) map[string]bool {
+	counts, _ := wordCounts(wordsPerSentence)
+	words := make([]string, 0, len(counts))
+	for word := range counts {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	limit := int(float64(len(words)) * luhnSignificantRatio)
+	if limit < 1 {
+		limit = 1
+	}
+	significant := map[string]bool{}
+	for i, word := range words {
+		if i >= limit || counts[word] < 2 {
+			break
+		}
+		significant[word] = true
+	}
+	if len(significant) == 0 {
+		for _, word := range words {
+			significant[word] = true
+		}
+	}
+	return significant
+}
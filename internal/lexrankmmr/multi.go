@@ -0,0 +1,52 @@
+package lexrankmmr
+
+// Source is a document given to Summarizer.SummarizeDocuments
+type Source struct {
+	// ID is reported as ScoredSentence.Source
+	ID   string
+	Text string
+}
+
+// DuplicateThreshold set config.duplicateThreshold. With SummarizeDocuments,
+// a sentence whose similarity to a higher ranked sentence of another source
+// is at least the threshold is not used in the summary.
+func DuplicateThreshold(threshold float64) Option {
+	return func(args *config) error {
+		if threshold < 0 || threshold > 1 {
+			return &OptionError{Option: "duplicateThreshold", Err: ErrOutOfRange}
+		}
+		args.duplicateThreshold = threshold
+		return nil
+	}
+}
+
+// removeDuplicates drops from s.scores the sentences nearly identical to a
+// higher ranked sentence of another source, so that a fact reported by
+// several sources appears once. It keeps the sentence of the first source
+// among equal scores.
+func (s *SummaryData) removeDuplicates() {
+	if len(s.sources) < 2 {
+		return
+	}
+	isKept := make([]bool, len(s.similarity))
+	keptPerSource := make([]int, len(s.sources))
+	kept := make([]ScoredSentence, 0, len(s.scores))
+	for _, candidate := range s.scores {
+		source := s.sentenceSources[candidate.Id]
+		// the sentences not in the row of candidate have similarity 0
+		duplicate := s.duplicateThreshold == 0 && len(kept) > keptPerSource[source]
+		row := s.similarity[candidate.Id]
+		for k, j := range row.Sentences {
+			if isKept[j] && s.sentenceSources[j] != source && row.Similarities[k] >= s.duplicateThreshold {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			kept = append(kept, candidate)
+			isKept[candidate.Id] = true
+			keptPerSource[source]++
+		}
+	}
+	s.scores = kept
+}
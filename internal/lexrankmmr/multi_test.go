@@ -0,0 +1,80 @@
+package lexrankmmr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// duplicateData returns SummaryData of three sentences ranked in order,
+// 0 and 2 of the first source and 1 of the second. Sentence 1 has
+// similarity to 0, and sentence 2 repeats 0.
+func duplicateData(t *testing.T, similarity float64, options ...Option) *SummaryData {
+	t.Helper()
+	s := &SummaryData{config: defaultConfig()}
+	if err := s.config.apply(options); err != nil {
+		t.Fatal(err)
+	}
+	s.sources = []Source{{ID: "a"}, {ID: "b"}}
+	s.sentenceSources = []int{0, 1, 0}
+	s.similarity = SimilarityGraph{
+		{Sentences: []int32{0, 1, 2}, Similarities: []float64{1, similarity, 1}},
+		{Sentences: []int32{0, 1}, Similarities: []float64{similarity, 1}},
+		{Sentences: []int32{0, 2}, Similarities: []float64{1, 1}},
+	}
+	s.scores = []ScoredSentence{{Id: 0, Score: 3}, {Id: 1, Score: 2}, {Id: 2, Score: 1}}
+	return s
+}
+
+func TestRemoveDuplicates(t *testing.T) {
+	tests := []struct {
+		name       string
+		similarity float64
+		options    []Option
+		want       []int
+	}{
+		{"at the default threshold", defaultDuplicate, nil, []int{0, 2}},
+		{"below the default threshold", defaultDuplicate - 1e-9, nil, []int{0, 1, 2}},
+		{"above the threshold", 0.95, nil, []int{0, 2}},
+		{"threshold 1", 0.95, []Option{DuplicateThreshold(1)}, []int{0, 1, 2}},
+		// the sentences of another source are duplicates even without a common term
+		{"threshold 0", 0, []Option{DuplicateThreshold(0)}, []int{0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := duplicateData(t, tt.similarity, tt.options...)
+			s.removeDuplicates()
+			var got []int
+			for _, v := range s.scores {
+				got = append(got, v.Id)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemoveDuplicatesOfSources(t *testing.T) {
+	words := [][]string{
+		{"猫", "犬"},
+		{"猫", "犬"},
+		{"車", "電車"},
+		{"猫", "犬"},
+		{"空", "海"},
+	}
+	s := tokenizedData(t, words, []int{0, 0, 1, 1, 1})
+	if err := s.rank(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.removeDuplicates()
+	kept := map[int]bool{}
+	for _, v := range s.scores {
+		kept[v.Id] = true
+	}
+	// the repeat within the first source is kept, and that of the second source is not
+	want := map[int]bool{0: true, 1: true, 2: true, 4: true}
+	if !reflect.DeepEqual(kept, want) {
+		t.Errorf("kept %v, want %v", kept, want)
+	}
+}
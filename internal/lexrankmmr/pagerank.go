@@ -0,0 +1,115 @@
+package lexrankmmr
+
+import (
+	"context"
+	"math"
+)
+
+// PageRankStats reports how the PageRank iteration ended
+type PageRankStats struct {
+	Iterations int     `json:"iterations"`
+	Change     float64 `json:"change"`
+	Converged  bool    `json:"converged"`
+}
+
+// graph is a weighted directed graph of sentences ranked by PageRank.
+// It follows github.com/dcadenas/pagerank, but the random surfer follows
+// an out link with probability proportional to its weight.
+// With every weight equal to 1 it is the unweighted PageRank.
+type graph struct {
+	inLinks   [][]edge
+	outWeight []float64
+}
+
+type edge struct {
+	from   int
+	weight float64
+}
+
+func newGraph(n int) *graph {
+	return &graph{
+		inLinks:   make([][]edge, n),
+		outWeight: make([]float64, n),
+	}
+}
+
+func (g *graph) link(from, to int, weight float64) {
+	g.inLinks[to] = append(g.inLinks[to], edge{from: from, weight: weight})
+	g.outWeight[from] += weight
+}
+
+func (g *graph) danglingNodes() []int {
+	danglingNodes := make([]int, 0, len(g.outWeight))
+	for i, outWeight := range g.outWeight {
+		if outWeight == 0 {
+			danglingNodes = append(danglingNodes, i)
+		}
+	}
+	return danglingNodes
+}
+
+func (g *graph) step(damping float64, teleport, p []float64, danglingNodes []int) []float64 {
+	innerProduct := 0.0
+	for _, danglingNode := range danglingNodes {
+		innerProduct += p[danglingNode]
+	}
+	vsum := 0.0
+	v := make([]float64, len(p))
+	for i, inLinks := range g.inLinks {
+		ksum := 0.0
+		for _, e := range inLinks {
+			ksum += p[e.from] * e.weight / g.outWeight[e.from]
+		}
+		v[i] = damping*(ksum+innerProduct*teleport[i]) + (1-damping)*teleport[i]
+		vsum += v[i]
+	}
+	inverseOfSum := 1.0 / vsum
+	for i := range v {
+		v[i] *= inverseOfSum
+	}
+	return v
+}
+
+// rank returns the PageRank of each node.
+// The random surfer jumps to a node with probability proportional to
+// personalization, or uniformly when it is nil or all zero, which is the
+// topic-sensitive PageRank of Haveliwala.
+// It stops when the L1 change is within tolerance or after maxIterations.
+func (g *graph) rank(ctx context.Context, damping, tolerance float64, maxIterations int, personalization []float64) ([]float64, PageRankStats, error) {
+	size := len(g.inLinks)
+	p := make([]float64, size)
+	if size == 0 {
+		return p, PageRankStats{Converged: true}, nil
+	}
+	teleport := make([]float64, size)
+	var sum float64
+	for _, x := range personalization {
+		sum += x
+	}
+	for i := range teleport {
+		if sum > 0 {
+			teleport[i] = personalization[i] / sum
+		} else {
+			teleport[i] = 1.0 / float64(size)
+		}
+	}
+	danglingNodes := g.danglingNodes()
+	for i := range p {
+		p[i] = 1.0 / float64(size)
+	}
+	stats := PageRankStats{Change: 2.0}
+	for stats.Change > tolerance && stats.Iterations < maxIterations {
+		if err := checkContext(ctx); err != nil {
+			return nil, stats, err
+		}
+		newP := g.step(damping, teleport, p, danglingNodes)
+		stats.Change = 0
+		for i := range p {
+			stats.Change += math.Abs(p[i] - newP[i])
+		}
+		stats.Iterations++
+		p = newP
+	}
+	stats.Converged = stats.Change <= tolerance
+	return p, stats, nil
+}
@@ -0,0 +1,71 @@
+package lexrankmmr
+
+// Stage is a step of Summarize reported by Progress
+type Stage string
+
+// Stages of Summarize in order
+const (
+	StageSegment    Stage = "segment"
+	StageTokenize   Stage = "tokenize"
+	StageSimilarity Stage = "similarity"
+	StageRank       Stage = "rank"
+	StageSelect     Stage = "select"
+)
+
+// Progress reports how far Summarize has gone
+type Progress struct {
+	Stage Stage `json:"stage"`
+	// Sentences is the number of sentences of the text, 0 before StageTokenize
+	Sentences int `json:"sentences"`
+	// Percent is an estimate of the work done, from 0 to 100
+	Percent float64 `json:"percent"`
+}
+
+// stages lists the stages in order with the percent at their start.
+// Tokenization and the similarity of sentences take most of the time.
+var stages = []struct {
+	stage   Stage
+	percent float64
+}{
+	{StageSegment, 0},
+	{StageTokenize, 5},
+	{StageSimilarity, 35},
+	{StageRank, 75},
+	{StageSelect, 90},
+}
+
+// OnProgress set config.progress, called as Summarize goes on.
+// It is called at least once per stage and at most once per percent,
+// from the goroutine running Summarize, and must return quickly.
+func OnProgress(progress func(Progress)) Option {
+	return func(args *config) error {
+		args.progress = progress
+		return nil
+	}
+}
+
+// report calls config.progress when stage has done done of total steps
+func (s *SummaryData) report(stage Stage, done, total int) {
+	if s.progress == nil {
+		return
+	}
+	var percent float64
+	for k, st := range stages {
+		if st.stage != stage {
+			continue
+		}
+		next := 100.0
+		if k+1 < len(stages) {
+			next = stages[k+1].percent
+		}
+		percent = st.percent
+		if total > 0 {
+			percent += (next - st.percent) * float64(done) / float64(total)
+		}
+	}
+	if stage == s.lastStage && int(percent) == int(s.lastPercent) {
+		return
+	}
+	s.lastStage, s.lastPercent = stage, percent
+	s.progress(Progress{Stage: stage, Sentences: len(s.sentences), Percent: percent})
+}
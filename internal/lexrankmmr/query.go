@@ -0,0 +1,100 @@
+package lexrankmmr
+
+import (
+	"math"
+	"sort"
+)
+
+// Query set config.query. The sentences similar to the query are ranked
+// higher by LexRank and TextRank, and are more relevant in MMR.
+// The empty query summarizes the whole document.
+func Query(query string) Option {
+	return func(args *config) error {
+		args.query = query
+		return nil
+	}
+}
+
+// splitQuery sets the words of the query, tokenized as the sentences are
+func (s *SummaryData) splitQuery() {
+	if s.query == "" {
+		return
+	}
+	s.queryWords, _ = s.words(s.query)
+}
+
+// queryRelevance returns the cosine similarity of the TF-IDF vector of each
+// sentence of doc to that of doc.Query. Words of the query which are not in
+// the document are ignored.
+func (doc *Document) queryRelevance() []float64 {
+	vocabulary, vectors := doc.vectors()
+	idf := doc.idf
+	if idf == nil {
+		idf = localIDF
+	}
+	counts := map[int]int{}
+	for _, word := range doc.Query {
+		if id, ok := vocabulary[word]; ok {
+			counts[id]++
+		}
+	}
+	words := make([]string, len(vocabulary))
+	for word, id := range vocabulary {
+		words[id] = word
+	}
+	df := map[int]int{}
+	for _, v := range vectors {
+		for _, id := range v.ids {
+			if _, ok := counts[id]; ok {
+				df[id]++
+			}
+		}
+	}
+
+	query := sparseVector{}
+	for id := range counts {
+		query.ids = append(query.ids, id)
+	}
+	sort.Ints(query.ids)
+	var sum float64
+	for _, id := range query.ids {
+		w := float64(counts[id]) * idf(words[id], df[id], len(vectors))
+		query.weights = append(query.weights, w)
+		sum += w * w
+	}
+	query.norm = math.Sqrt(sum)
+
+	relevance := make([]float64, len(vectors))
+	for i, v := range vectors {
+		relevance[i] = query.cosine(v)
+	}
+	return relevance
+}
+
+// relevance returns the relevance of each sentence of s.scores used by MMR,
+// the score divided by the highest score. With a query it is the mean of that
+// and the similarity to the query divided by the highest similarity.
+func (s *SummaryData) relevance() []float64 {
+	relevance := make([]float64, len(s.scores))
+	if len(s.scores) == 0 {
+		return relevance
+	}
+	maxScore := s.scores[0].Score
+	var maxQuery float64
+	for _, r := range s.queryRelevance {
+		maxQuery = math.Max(maxQuery, r)
+	}
+	for i, v := range s.scores {
+		if maxScore > 0 {
+			relevance[i] = v.Score / maxScore
+		}
+		if s.queryRelevance != nil {
+			var r float64
+			if maxQuery > 0 {
+				r = s.queryRelevance[v.Id] / maxQuery
+			}
+			relevance[i] = (relevance[i] + r) / 2
+		}
+	}
+	return relevance
+}
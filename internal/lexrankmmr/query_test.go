@@ -0,0 +1,102 @@
+package lexrankmmr
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestQueryRelevance(t *testing.T) {
+	tests := []struct {
+		name  string
+		query []string
+		// related are the sentences with a positive relevance
+		related []int
+	}{
+		{"one sentence", []string{"車"}, []int{4}},
+		{"words not in the document are ignored", []string{"車", "飛行機"}, []int{4}},
+		{"several sentences", []string{"犬", "鳥"}, []int{0, 1, 2}},
+		{"no word of the document", []string{"飛行機"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relevance := (&Document{Words: animals, Query: tt.query}).queryRelevance()
+			if len(relevance) != len(animals) {
+				t.Fatalf("got %d relevances for %d sentences", len(relevance), len(animals))
+			}
+			var related []int
+			for i, r := range relevance {
+				if r < 0 || r > 1+1e-9 {
+					t.Errorf("relevance of sentence %d = %v, want a cosine in [0, 1]", i, r)
+				}
+				if r > 0 {
+					related = append(related, i)
+				}
+			}
+			if !reflect.DeepEqual(related, tt.related) {
+				t.Errorf("related sentences = %v, want %v (relevance %v)", related, tt.related, relevance)
+			}
+		})
+	}
+}
+
+// rankedScores returns the score of each sentence ranked by algorithm with the query words
+func rankedScores(t *testing.T, algorithm Algorithm, query []string) ([]float64, *SummaryData) {
+	t.Helper()
+	s := tokenizedData(t, animals, nil, UseAlgorithm(algorithm))
+	s.queryWords = query
+	if err := s.rank(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	scores := make([]float64, len(s.scores))
+	for _, v := range s.scores {
+		scores[v.Id] = v.Score
+	}
+	return scores, s
+}
+
+func TestQueryBiasesPageRank(t *testing.T) {
+	for _, algorithm := range []Algorithm{LexRank, TextRank} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			plain, s := rankedScores(t, algorithm, nil)
+			if s.queryRelevance != nil {
+				t.Errorf("queryRelevance = %v without a query, want nil", s.queryRelevance)
+			}
+
+			// the personalization vector moves the rank towards the query sentence
+			biased, s := rankedScores(t, algorithm, []string{"車"})
+			if got := order(biased)[0]; got != 4 {
+				t.Errorf("order = %v, want the query sentence 4 first (scores %v)", order(biased), biased)
+			}
+			if biased[4] <= plain[4] {
+				t.Errorf("score of the query sentence = %v, want above %v without the query", biased[4], plain[4])
+			}
+			if len(s.queryRelevance) != len(animals) || s.queryRelevance[4] <= 0 {
+				t.Errorf("queryRelevance = %v, want it for every sentence", s.queryRelevance)
+			}
+
+			// a query without a word of the document teleports uniformly
+			unrelated, _ := rankedScores(t, algorithm, []string{"飛行機"})
+			for i := range plain {
+				if diff := unrelated[i] - plain[i]; diff > 1e-9 || diff < -1e-9 {
+					t.Errorf("scores = %v with an unrelated query, want %v", unrelated, plain)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestQueryMmrRelevance(t *testing.T) {
+	_, s := rankedScores(t, LexRank, []string{"車"})
+	relevance := s.relevance()
+	for i, v := range s.scores {
+		want := v.Score / s.scores[0].Score / 2
+		if v.Id == 4 {
+			want += 0.5
+		}
+		if diff := relevance[i] - want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("relevance of sentence %d = %v, want the mean %v of its score and the query", v.Id, relevance[i], want)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package lexrankmmr
+
+import (
+	"context"
+	"math"
+)
+
+// Ranker scores the sentences of a Document.
+// A higher score means a more important sentence.
+type Ranker interface {
+	// Name returns the name reported in Result.Algorithm
+	Name() string
+	// Rank returns one score for each sentence of doc
+	Rank(ctx context.Context, doc *Document) (Ranking, error)
+}
+
+// Document is a tokenized document given to Ranker
+type Document struct {
+	// Sentences of the document
+	Sentences []Sentence
+	// Words of each sentence
+	Words [][]string
+	// Similarity is the similarity between each pair of sentences
+	Similarity SimilarityGraph
+	// Query is the words of the query, nil without a query
+	Query []string
+	// Relevance is the similarity of each sentence to the query, nil without a query
+	Relevance []float64
+
+	idf idfFunc
+}
+
+// vectors returns the vocabulary and the TF-IDF vectors of the sentences of doc
+func (doc *Document) vectors() (map[string]int, []sparseVector) {
+	idf := doc.idf
+	if idf == nil {
+		idf = localIDF
+	}
+	vocabulary := newVocabulary(doc.Words)
+	return vocabulary, tfidfVectors(doc.Words, vocabulary, idf)
+}
+
+// Ranking is the result of Ranker.Rank
+type Ranking struct {
+	// Scores has one score for each sentence of the document
+	Scores []float64
+	// PageRank is set by rankers using PageRank
+	PageRank *PageRankStats
+}
+
+// Algorithm is the name of a built-in Ranker
+type Algorithm string
+
+// Built-in algorithms
+const (
+	// LexRank ranks sentences by PageRank over the TF-IDF cosine similarity graph
+	LexRank Algorithm = "lexrank"
+	// TextRank ranks sentences by PageRank over the word overlap graph
+	TextRank Algorithm = "textrank"
+	// Centroid ranks sentences by their similarity to the centroid of the document
+	Centroid Algorithm = "centroid"
+	// LSA ranks sentences by their weight in the main topics of the term-sentence matrix
+	LSA Algorithm = "lsa"
+	// SumBasic ranks sentences by the average probability of their words
+	SumBasic Algorithm = "sumbasic"
+	// KLSum ranks sentences by how close they bring the summary to the word distribution of the document
+	KLSum Algorithm = "klsum"
+	// Luhn ranks sentences by their clusters of significant words
+	Luhn Algorithm = "luhn"
+)
+
+// Algorithms lists the built-in algorithms
+var Algorithms = []Algorithm{LexRank, TextRank, Centroid, LSA, SumBasic, KLSum, Luhn}
+
+// UseAlgorithm set config.algorithm
+func UseAlgorithm(algorithm Algorithm) Option {
+	return func(args *config) error {
+		for _, a := range Algorithms {
+			if a == algorithm {
+				args.algorithm = algorithm
+				args.ranker = nil
+				return nil
+			}
+		}
+		return &OptionError{Option: "algorithm", Err: ErrOutOfRange}
+	}
+}
+
+// UseRanker set config.ranker, a Ranker used instead of the built-in algorithms
+func UseRanker(ranker Ranker) Option {
+	return func(args *config) error {
+		args.ranker = ranker
+		return nil
+	}
+}
+
+// newRanker returns the Ranker selected by c
+func (c *config) newRanker() Ranker {
+	if c.ranker != nil {
+		return c.ranker
+	}
+	switch c.algorithm {
+	case TextRank:
+		return textRanker{damping: c.damping, tolerance: c.tolerance, maxIterations: c.maxIterations}
+	case Centroid:
+		return centroidRanker{}
+	case LSA:
+		return lsaRanker{dimensions: defaultLSADimensions}
+	case SumBasic:
+		return sumBasicRanker{}
+	case KLSum:
+		return klSumRanker{}
+	case Luhn:
+		return luhnRanker{}
+	}
+	return lexRanker{
+		threshold:     c.threshold,
+		damping:       c.damping,
+		tolerance:     c.tolerance,
+		maxIterations: c.maxIterations,
+		mode:          c.mode,
+	}
+}
+
+type lexRanker struct {
+	threshold     float64
+	damping       float64
+	tolerance     float64
+	maxIterations int
+	mode          Mode
+}
+
+func (r lexRanker) Name() string {
+	return string(LexRank)
+}
+
+// Rank links the pairs of sentences of doc.Similarity. The pairs without a
+// common term are not linked, even with a threshold of 0.
+func (r lexRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	graph := newGraph(len(doc.Similarity))
+	for i, row := range doc.Similarity {
+		// every sentence is similar to itself
+		graph.link(i, i, 1)
+		for k, j := range row.Sentences {
+			similarity := row.Similarities[k]
+			switch {
+			case r.mode == Continuous && similarity > 0:
+				graph.link(i, int(j), similarity)
+			case r.mode == Discrete && similarity >= r.threshold:
+				graph.link(i, int(j), 1)
+			}
+		}
+	}
+	ranks, stats, err := graph.rank(ctx, r.damping, r.tolerance, r.maxIterations, doc.Relevance)
+	if err != nil {
+		return Ranking{}, err
+	}
+	return Ranking{Scores: ranks, PageRank: &stats}, nil
+}
+
+// scoresFromOrder gives descending scores in (0, 1] to sentences picked in order
+func scoresFromOrder(order []int, n int) []float64 {
+	scores := make([]float64, n)
+	for rank, i := range order {
+		scores[i] = float64(n-rank) / float64(n)
+	}
+	return scores
+}
+
+// wordCounts returns the count of each word and the number of words in the document
+func wordCounts(wordsPerSentence [][]string) (map[string]int, int) {
+	counts := map[string]int{}
+	total := 0
+	for _, words := range wordsPerSentence {
+		for _, word := range words {
+			counts[word]++
+			total++
+		}
+	}
+	return counts, total
+}
+
+func norm(v []float64) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
@@ -0,0 +1,151 @@
+package lexrankmmr
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// tokenizedData returns SummaryData whose sentences are already split into
+// words, with their similarity computed, ready to be ranked. sources gives the
+// source of each sentence, and all sentences are of one source if it is nil.
+func tokenizedData(t *testing.T, words [][]string, sources []int, options ...Option) *SummaryData {
+	t.Helper()
+	s := &SummaryData{config: defaultConfig()}
+	if err := s.config.apply(options); err != nil {
+		t.Fatal(err)
+	}
+	if sources == nil {
+		sources = make([]int, len(words))
+	}
+	for i, w := range words {
+		for len(s.sources) <= sources[i] {
+			s.sources = append(s.sources, Source{ID: fmt.Sprintf("source-%d", len(s.sources))})
+		}
+		text := strings.Join(w, "") + "。"
+		s.sentences = append(s.sentences, Sentence{Text: text})
+		s.originalSentences = append(s.originalSentences, text)
+		s.tokenCounts = append(s.tokenCounts, len(w))
+	}
+	s.sentenceSources = sources
+	s.wordsPerSentence = words
+	s.buildVocabulary()
+	s.calculateVectors()
+	if err := s.createSimilarityGraph(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// animals is a document about animals with one sentence off the topic
+var animals = [][]string{
+	{"猫", "犬", "鳥", "魚"},
+	{"猫", "犬"},
+	{"猫", "鳥"},
+	{"猫", "魚"},
+	{"車", "電車"},
+}
+
+// order returns the sentences by descending score, the first one among equal scores
+func order(scores []float64) []int {
+	ids := make([]int, len(scores))
+	for i := range ids {
+		ids[i] = i
+	}
+	sort.SliceStable(ids, func(i, j int) bool {
+		return scores[ids[i]] > scores[ids[j]]
+	})
+	return ids
+}
+
+func TestRankers(t *testing.T) {
+	luhn := [][]string{
+		// 猫 and 犬 are the significant words, and more than 4 words between them break a cluster
+		{"猫", "犬", "f1", "f2", "f3", "f4", "f5", "猫"},
+		{"猫", "f6", "犬", "f7", "猫"},
+		{"猫", "犬", "猫"},
+		{"f8", "f9", "f10", "f11", "f12", "f13", "f14", "f15", "f16", "f17", "f18", "f19", "f20"},
+	}
+	tests := []struct {
+		name   string
+		ranker Ranker
+		words  [][]string
+		// first and last are the sentences expected first and last,
+		// or want is the whole order when it is not nil
+		first, last int
+		want        []int
+	}{
+		{"textrank links shared words", textRanker{damping: defaultDamping, tolerance: defaultTolerance, maxIterations: defaultMaxIterations}, animals, 0, 4, nil},
+		{"centroid", centroidRanker{}, animals, 0, 4, nil},
+		{"lsa main topic", lsaRanker{dimensions: 1}, animals, 0, 4, nil},
+		// the short sentences of frequent words come first, and the words
+		// of the first sentence are discounted by then
+		{"sumbasic discounts picked words", sumBasicRanker{}, animals, 1, 0, []int{1, 2, 3, 4, 0}},
+		// the sentence off the topic brings the missing words right after the whole topic
+		{"klsum covers the document", klSumRanker{}, animals, 0, 3, []int{0, 4, 1, 2, 3}},
+		{"luhn clusters", luhnRanker{}, luhn, 2, 3, []int{2, 0, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranking, err := tt.ranker.Rank(context.Background(), &Document{Words: tt.words})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ranking.Scores) != len(tt.words) {
+				t.Fatalf("got %d scores for %d sentences", len(ranking.Scores), len(tt.words))
+			}
+			got := order(ranking.Scores)
+			if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v (scores %v)", got, tt.want, ranking.Scores)
+			}
+			if got[0] != tt.first || got[len(got)-1] != tt.last {
+				t.Errorf("order = %v, want %d first and %d last (scores %v)", got, tt.first, tt.last, ranking.Scores)
+			}
+		})
+	}
+}
+
+func TestRankerAlgorithm(t *testing.T) {
+	for _, algorithm := range Algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			s := tokenizedData(t, animals, nil, UseAlgorithm(algorithm))
+			if err := s.rank(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if s.Algorithm != string(algorithm) {
+				t.Errorf("Algorithm = %q, want %q", s.Algorithm, algorithm)
+			}
+			if len(s.scores) != len(animals) {
+				t.Errorf("got %d scores for %d sentences", len(s.scores), len(animals))
+			}
+			usesPageRank := algorithm == LexRank || algorithm == TextRank
+			if (s.PageRank != nil) != usesPageRank {
+				t.Errorf("PageRank = %+v, want it only for PageRank algorithms", s.PageRank)
+			}
+		})
+	}
+}
+
+// constantRanker gives every sentence the same score
+type constantRanker struct{}
+
+func (constantRanker) Name() string {
+	return "constant"
+}
+
+func (constantRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	return Ranking{Scores: make([]float64, len(doc.Words))}, nil
+}
+
+func TestUseRanker(t *testing.T) {
+	s := tokenizedData(t, animals, nil, UseAlgorithm(TextRank), UseRanker(constantRanker{}))
+	if err := s.rank(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Algorithm != "constant" {
+		t.Errorf("Algorithm = %q, want the name of the custom ranker", s.Algorithm)
+	}
+}
@@ -0,0 +1,268 @@
+package lexrankmmr
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Sentence is a sentence of the input text with its position.
+// Start and End are rune offsets, StartByte and EndByte are byte offsets
+// into the original text. Text is equal to the original text in that range.
+type Sentence struct {
+	Text      string
+	Start     int
+	End       int
+	StartByte int
+	EndByte   int
+}
+
+// brackets maps opening brackets and quotes to their closing pair
+var brackets = map[rune]rune{
+	'「': '」',
+	'『': '』',
+	'（': '）',
+	'(': ')',
+	'【': '】',
+	'〔': '〕',
+	'［': '］',
+	'[': ']',
+	'｛': '｝',
+	'{': '}',
+	'〈': '〉',
+	'《': '》',
+	'“': '”',
+	'‘': '’',
+}
+
+// maxBracketRunes is how far the closer of a bracket is looked for. A bracket
+// which is not closed within it, or before the end of its line, is treated as
+// an ordinary character, so that a stray "(" of a kaomoji does not join the
+// rest of the text into one sentence.
+const maxBracketRunes = 200
+
+// openBracket is a bracket waiting for its closer at runes[at]
+type openBracket struct {
+	closer rune
+	at     int
+}
+
+var closingBrackets = func() map[rune]bool {
+	m := map[rune]bool{}
+	for _, c := range brackets {
+		m[c] = true
+	}
+	return m
+}()
+
+// abbreviations are English words which end with a period but do not end a sentence
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true,
+	"st": true, "jr": true, "sr": true, "vs": true, "etc": true,
+	"e.g": true, "i.e": true, "no": true, "inc": true, "ltd": true,
+	"co": true, "corp": true, "fig": true, "vol": true, "p": true,
+}
+
+var bullets = map[rune]bool{
+	'・': true, '•': true, '●': true, '○': true, '■': true, '□': true,
+	'◆': true, '◇': true, '▪': true, '※': true, '-': true, '*': true,
+	'+': true, '－': true,
+}
+
+func isTerminatorRune(r rune) bool {
+	switch r {
+	case '。', '．', '！', '？', '!', '?', '｡':
+		return true
+	}
+	return false
+}
+
+// Segment splits text into sentences.
+//
+// A sentence ends at 。．！？!? or at a period which ends an English sentence,
+// but not inside 「」（）and other brackets. Brackets are not followed across
+// lines or beyond maxBracketRunes. Blank lines and bulleted lines are
+// always boundaries. Terminal punctuation and closing brackets stay in the
+// sentence, and a final sentence without a terminator is kept.
+func Segment(text string) []Sentence {
+	runes := []rune(text)
+	n := len(runes)
+	byteOffsets := make([]int, n+1)
+	offset := 0
+	for i, r := range runes {
+		byteOffsets[i] = offset
+		offset += len(string(r))
+	}
+	byteOffsets[n] = offset
+
+	sentences := []Sentence{}
+	start := 0
+	emit := func(end int) {
+		for start < end && unicode.IsSpace(runes[start]) {
+			start++
+		}
+		e := end
+		for e > start && unicode.IsSpace(runes[e-1]) {
+			e--
+		}
+		if hasContent(runes[start:e]) {
+			sentences = append(sentences, Sentence{
+				Text:      text[byteOffsets[start]:byteOffsets[e]],
+				Start:     start,
+				End:       e,
+				StartByte: byteOffsets[start],
+				EndByte:   byteOffsets[e],
+			})
+		}
+		start = end
+	}
+
+	var stack []openBracket
+	// ignored are the positions of the brackets which are not closed in reach
+	ignored := map[int]bool{}
+	marker := bulletMarker(runes, 0)
+	bulletLine := marker >= 0
+	for i := 0; i <= n; i++ {
+		if marker >= 0 {
+			// skip the list marker so that "1." does not end a sentence
+			i, marker = marker, -1
+			if i >= n {
+				break
+			}
+		}
+		if len(stack) > 0 && (i == n || runes[i] == '\n' || i-stack[0].at > maxBracketRunes) {
+			// the oldest bracket is not closed in reach, scan again without it
+			ignored[stack[0].at] = true
+			i = stack[0].at
+			stack = nil
+			continue
+		}
+		if i == n {
+			break
+		}
+		r := runes[i]
+		switch {
+		case r == '\n':
+			marker = bulletMarker(runes, i+1)
+			if bulletLine || marker >= 0 || isBlankLine(runes, i+1) {
+				emit(i + 1)
+			}
+			bulletLine = marker >= 0
+		case closingBrackets[r]:
+			// a closer also closes the brackets opened after its pair,
+			// and a closer without a pair is ignored
+			for k := len(stack) - 1; k >= 0; k-- {
+				if stack[k].closer == r {
+					stack = stack[:k]
+					break
+				}
+			}
+		case brackets[r] != 0 && !ignored[i]:
+			stack = append(stack, openBracket{closer: brackets[r], at: i})
+		case len(stack) == 0 && isTerminator(runes, i):
+			j := i + 1
+			for j < n && (isTerminatorRune(runes[j]) || runes[j] == '.' || closingBrackets[runes[j]]) {
+				j++
+			}
+			emit(j)
+			i = j - 1
+		}
+	}
+	emit(n)
+	return sentences
+}
+
+// isTerminator reports whether runes[i] ends a sentence
+func isTerminator(runes []rune, i int) bool {
+	if isTerminatorRune(runes[i]) {
+		return true
+	}
+	if runes[i] != '.' {
+		return false
+	}
+	if i+1 < len(runes) {
+		next := runes[i+1]
+		if !unicode.IsSpace(next) && !closingBrackets[next] && next != '.' {
+			// 3.14, example.com
+			return false
+		}
+	}
+	word := precedingWord(runes, i)
+	if abbreviations[strings.ToLower(word)] {
+		return false
+	}
+	if len([]rune(word)) == 1 && unicode.IsUpper([]rune(word)[0]) {
+		// initials such as "J. Smith"
+		return false
+	}
+	return true
+}
+
+// precedingWord returns the latin word, including inner periods, before runes[i]
+func precedingWord(runes []rune, i int) string {
+	j := i
+	for j > 0 && (runes[j-1] < unicode.MaxASCII && (unicode.IsLetter(runes[j-1]) || runes[j-1] == '.')) {
+		j--
+	}
+	return string(runes[j:i])
+}
+
+// isBlankLine reports whether the line starting at runes[i] has only spaces
+func isBlankLine(runes []rune, i int) bool {
+	for ; i < len(runes) && runes[i] != '\n'; i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// bulletMarker returns the end of the list marker of the line starting at runes[i],
+// or -1 if the line is not a list item
+func bulletMarker(runes []rune, i int) int {
+	for i < len(runes) && runes[i] != '\n' && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	if i >= len(runes) {
+		return -1
+	}
+	if bullets[runes[i]] {
+		if runes[i] < unicode.MaxASCII && (i+1 >= len(runes) || !unicode.IsSpace(runes[i+1])) {
+			// "-1" or "*note" are not list items
+			return -1
+		}
+		return i + 1
+	}
+	if '①' <= runes[i] && runes[i] <= '⑳' {
+		return i + 1
+	}
+	// 1. 1) (1)
+	j := i
+	if runes[j] == '(' || runes[j] == '（' {
+		j++
+	}
+	k := j
+	for k < len(runes) && unicode.IsDigit(runes[k]) {
+		k++
+	}
+	if k == j || k >= len(runes) {
+		return -1
+	}
+	switch runes[k] {
+	case '.', '．':
+		if k+1 < len(runes) && unicode.IsSpace(runes[k+1]) {
+			return k + 1
+		}
+	case ')', '）':
+		return k + 1
+	}
+	return -1
+}
+
+func hasContent(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
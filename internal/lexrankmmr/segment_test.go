@@ -0,0 +1,72 @@
+package lexrankmmr
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSegment(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"terminators", "今日は晴れ。明日は雨！明後日は？", []string{"今日は晴れ。", "明日は雨！", "明後日は？"}},
+		{"decimals", "円周率は3.14です。次の文。", []string{"円周率は3.14です。", "次の文。"}},
+		{"domains", "詳しくはexample.comを見て。", []string{"詳しくはexample.comを見て。"}},
+		{"abbreviations", "Mr. Smith met Dr. Brown. They talked.", []string{"Mr. Smith met Dr. Brown.", "They talked."}},
+		{"initials", "J. Smith wrote it. It sold well.", []string{"J. Smith wrote it.", "It sold well."}},
+		{"quotes", "彼は「はい。わかりました。」と言った。次。", []string{"彼は「はい。わかりました。」と言った。", "次。"}},
+		{"nested quotes", "「『待て。』と言われた。」と話した。次。", []string{"「『待て。』と言われた。」と話した。", "次。"}},
+		{"bullets", "・りんご\n・みかん\n1. ぶどう\n(2) もも", []string{"・りんご", "・みかん", "1. ぶどう", "(2) もも"}},
+		{"blank line", "見出し\n\n本文です", []string{"見出し", "本文です"}},
+		{"wrapped line", "長い文が\n続きます。次。", []string{"長い文が\n続きます。", "次。"}},
+		{"unterminated", "今日は晴れ。明日は", []string{"今日は晴れ。", "明日は"}},
+		{"unclosed bracket", "顔文字(^^ 今日は晴れ。明日は雨。明後日は雪。", []string{"顔文字(^^ 今日は晴れ。", "明日は雨。", "明後日は雪。"}},
+		{"unclosed quote in line", "「こんにちは。\n元気です。", []string{"「こんにちは。", "元気です。"}},
+		{"unclosed inside closed", "「あ(はい。」と言った。いいえ。", []string{"「あ(はい。」と言った。", "いいえ。"}},
+		{"stray closer", "はい)。次です。", []string{"はい)。", "次です。"}},
+		{"mismatched closer", "「あ（い」。次。", []string{"「あ（い」。", "次。"}},
+		{"empty", " \n ", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, s := range Segment(tt.text) {
+				got = append(got, s.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Segment(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSegmentLongBracket(t *testing.T) {
+	text := "("
+	for i := 0; i < maxBracketRunes; i++ {
+		text += "あ"
+	}
+	text += "。次の文。"
+	if got := len(Segment(text)); got != 2 {
+		t.Errorf("got %d sentences, want 2", got)
+	}
+}
+
+func TestSegmentOffsets(t *testing.T) {
+	text := "　一つ目。 two。\n\n三つ目"
+	want := []Sentence{
+		{Text: "一つ目。", Start: 1, End: 5, StartByte: 3, EndByte: 15},
+		{Text: "two。", Start: 6, End: 10, StartByte: 16, EndByte: 22},
+		{Text: "三つ目", Start: 12, End: 15, StartByte: 24, EndByte: 33},
+	}
+	got := Segment(text)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Segment(%q) = %+v, want %+v", text, got, want)
+	}
+	for _, s := range got {
+		if text[s.StartByte:s.EndByte] != s.Text || string([]rune(text)[s.Start:s.End]) != s.Text {
+			t.Errorf("offsets of %q do not match the text", s.Text)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package lexrankmmr
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+// SimilarityRow lists the sentences similar to a sentence in ascending order,
+// with their similarity to it
+type SimilarityRow struct {
+	Sentences    []int32
+	Similarities []float64
+}
+
+// SimilarityGraph is the similarity between each pair of sentences, with a row
+// for each sentence. It is sparse: only the pairs with a positive similarity
+// are listed, and the similarity of a sentence to itself, 1, is not listed.
+type SimilarityGraph []SimilarityRow
+
+// At returns the similarity of sentences i and j
+func (g SimilarityGraph) At(i, j int) float64 {
+	if i == j {
+		return 1
+	}
+	row := g[i]
+	k := sort.Search(len(row.Sentences), func(k int) bool {
+		return int(row.Sentences[k]) >= j
+	})
+	if k < len(row.Sentences) && int(row.Sentences[k]) == j {
+		return row.Similarities[k]
+	}
+	return 0
+}
+
+// posting is an occurrence of a term in a sentence of the inverted index
+type posting struct {
+	sentence int
+	weight   float64
+}
+
+// newInvertedIndex returns the postings of each term id, sorted by sentence
+func newInvertedIndex(vectors []sparseVector, terms int) [][]posting {
+	index := make([][]posting, terms)
+	for i, v := range vectors {
+		for k, id := range v.ids {
+			index[id] = append(index[id], posting{sentence: i, weight: v.weights[k]})
+		}
+	}
+	return index
+}
+
+// similarityWorker holds the scratch space of a worker of createSimilarityGraph
+type similarityWorker struct {
+	s       *SummaryData
+	index   [][]posting
+	dot     []float64
+	touched []int
+}
+
+// createSimilarityGraph computes the similarity of every pair of sentences.
+// With VocabularyVector only the sentences sharing a term with each other are
+// compared through an inverted index. Rows are computed by a pool of workers,
+// each listing the sentences after its sentence, and are then mirrored into
+// s.similarity.
+func (s *SummaryData) createSimilarityGraph(ctx context.Context) error {
+	n := len(s.originalSentences)
+	upper := make([]SimilarityRow, n)
+	var index [][]posting
+	if s.vectorModel != PositionalVector {
+		index = newInvertedIndex(s.vectors, len(s.vocabulary))
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var once sync.Once
+	var firstErr error
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	rows := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker := &similarityWorker{s: s, index: index, dot: make([]float64, n)}
+			for i := range rows {
+				if err := checkContext(ctx); err != nil {
+					fail(err)
+					continue
+				}
+				upper[i] = worker.row(i)
+			}
+		}()
+	}
+send:
+	for i := 0; i < n; i++ {
+		s.report(StageSimilarity, i, n)
+		select {
+		case rows <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(rows)
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+	s.similarity = mirror(upper)
+	return nil
+}
+
+// mirror returns the SimilarityGraph of upper, whose row i lists the sentences
+// after sentence i. The rows of upper are released as they are copied.
+func mirror(upper []SimilarityRow) SimilarityGraph {
+	degree := make([]int, len(upper))
+	total := 0
+	for i, row := range upper {
+		degree[i] += len(row.Sentences)
+		for _, j := range row.Sentences {
+			degree[j]++
+		}
+		total += 2 * len(row.Sentences)
+	}
+	sentences := make([]int32, total)
+	similarities := make([]float64, total)
+	g := make(SimilarityGraph, len(upper))
+	for i := range g {
+		g[i] = SimilarityRow{
+			Sentences:    sentences[:0:degree[i]],
+			Similarities: similarities[:0:degree[i]],
+		}
+		sentences, similarities = sentences[degree[i]:], similarities[degree[i]:]
+	}
+	// the sentences before i are appended to row i before those after it
+	for i, row := range upper {
+		g[i].Sentences = append(g[i].Sentences, row.Sentences...)
+		g[i].Similarities = append(g[i].Similarities, row.Similarities...)
+		for k, j := range row.Sentences {
+			g[j].Sentences = append(g[j].Sentences, int32(i))
+			g[j].Similarities = append(g[j].Similarities, row.Similarities[k])
+		}
+		upper[i] = SimilarityRow{}
+	}
+	return g
+}
+
+// row returns the sentences after sentence i which are similar to it
+func (w *similarityWorker) row(i int) SimilarityRow {
+	s := w.s
+	var row SimilarityRow
+	if s.vectorModel == PositionalVector {
+		// a sentence whose words are all filtered out is similar to no sentence
+		if isZero(s.tfIdfScores[i]) {
+			return row
+		}
+		for j := i + 1; j < len(s.tfIdfScores); j++ {
+			if isZero(s.tfIdfScores[j]) {
+				continue
+			}
+			if sim := positionalCosine(s.tfIdfScores[i], s.tfIdfScores[j]); sim > 0 {
+				row.Sentences = append(row.Sentences, int32(j))
+				row.Similarities = append(row.Similarities, math.Min(sim, 1))
+			}
+		}
+		return row
+	}
+
+	v := s.vectors[i]
+	if v.norm == 0 {
+		return row
+	}
+	w.touched = w.touched[:0]
+	for k, id := range v.ids {
+		postings := w.index[id]
+		start := sort.Search(len(postings), func(p int) bool {
+			return postings[p].sentence > i
+		})
+		for _, p := range postings[start:] {
+			if w.dot[p.sentence] == 0 {
+				w.touched = append(w.touched, p.sentence)
+			}
+			w.dot[p.sentence] += v.weights[k] * p.weight
+		}
+	}
+	sort.Ints(w.touched)
+	for _, j := range w.touched {
+		if w.dot[j] == 0 {
+			continue
+		}
+		// rounding can take the cosine of equal vectors above 1, which would
+		// exceed a redundancyThreshold of 1
+		sim := math.Min(w.dot[j]/(v.norm*s.vectors[j].norm), 1)
+		row.Sentences = append(row.Sentences, int32(j))
+		row.Similarities = append(row.Similarities, sim)
+		w.dot[j] = 0
+	}
+	return row
+}
+
+// positionalCosine returns the cosine similarity of positional vectors a and
+// b, neither of which is zero. The positions missing from the shorter vector
+// are 0.
+func positionalCosine(a, b []float64) float64 {
+	var dot float64
+	for k := 0; k < len(a) && k < len(b); k++ {
+		dot += a[k] * b[k]
+	}
+	return dot / (norm(a) * norm(b))
+}
+
+// isZero reports whether v has no non-zero element
+func isZero(v []float64) bool {
+	for _, x := range v {
+		if x != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package lexrankmmr
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+	"unsafe"
+)
+
+// similarityData returns SummaryData with n sentences of 5 to 30 words drawn
+// from a Zipf distribution over 20000 words, as words of a natural document are.
+// The 100 most frequent words are left out, as stopwords and the POS filter
+// would leave them out.
+func similarityData(n int) *SummaryData {
+	r := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(r, 1.1, 1, 20000)
+	s := &SummaryData{config: defaultConfig()}
+	s.originalSentences = make([]string, n)
+	s.wordsPerSentence = make([][]string, n)
+	for i := range s.wordsPerSentence {
+		words := make([]string, 5+r.Intn(25))
+		for j := range words {
+			w := zipf.Uint64()
+			for w < 100 {
+				w = zipf.Uint64()
+			}
+			words[j] = fmt.Sprintf("w%d", w)
+		}
+		s.wordsPerSentence[i] = words
+	}
+	s.buildVocabulary()
+	s.calculateVectors()
+	return s
+}
+
+// pairwiseSimilarity compares every pair of sentences on one goroutine,
+// as the similarity was computed before the inverted index
+func pairwiseSimilarity(s *SummaryData) [][]float64 {
+	n := len(s.vectors)
+	matrix := make([][]float64, n)
+	for i := range matrix {
+		matrix[i] = make([]float64, n)
+		matrix[i][i] = 1
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			matrix[i][j] = s.vectors[i].cosine(s.vectors[j])
+			matrix[j][i] = matrix[i][j]
+		}
+	}
+	return matrix
+}
+
+func TestSimilarityGraph(t *testing.T) {
+	s := similarityData(300)
+	if err := s.createSimilarityGraph(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	want := pairwiseSimilarity(s)
+	for i, row := range s.similarity {
+		for k := 1; k < len(row.Sentences); k++ {
+			if row.Sentences[k-1] >= row.Sentences[k] {
+				t.Fatalf("row %d is not in ascending order: %v", i, row.Sentences)
+			}
+		}
+		for j := range want[i] {
+			got := s.similarity.At(i, j)
+			if d := got - want[i][j]; d > 1e-12 || d < -1e-12 {
+				t.Fatalf("similarity(%d, %d) = %v, want %v", i, j, got, want[i][j])
+			}
+		}
+	}
+}
+
+// graphBytes returns the memory used by the rows of g
+func graphBytes(g SimilarityGraph) int {
+	bytes := len(g) * int(unsafe.Sizeof(SimilarityRow{}))
+	for _, row := range g {
+		bytes += 4*len(row.Sentences) + 8*len(row.Similarities)
+	}
+	return bytes
+}
+
+var similaritySizes = []int{1000, 10000}
+
+func BenchmarkSimilarityGraph(b *testing.B) {
+	for _, n := range similaritySizes {
+		b.Run(fmt.Sprintf("sentences=%d", n), func(b *testing.B) {
+			s := similarityData(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.createSimilarityGraph(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(graphBytes(s.similarity)), "graph-bytes")
+		})
+	}
+}
+
+func BenchmarkPairwiseSimilarity(b *testing.B) {
+	for _, n := range similaritySizes {
+		b.Run(fmt.Sprintf("sentences=%d", n), func(b *testing.B) {
+			s := similarityData(n)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				pairwiseSimilarity(s)
+			}
+		})
+	}
+}
+
+func BenchmarkPositionalTfIdf(b *testing.B) {
+	for _, n := range similaritySizes {
+		b.Run(fmt.Sprintf("sentences=%d", n), func(b *testing.B) {
+			s := similarityData(n)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := s.calculateTf(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+				if err := s.calculateIdf(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestPositionalSimilarityEmptySentence(t *testing.T) {
+	s := &SummaryData{config: defaultConfig()}
+	s.vectorModel = PositionalVector
+	s.originalSentences = []string{"猫が好き。", "はい。", "猫が好き。"}
+	s.wordsPerSentence = [][]string{{"猫", "好き"}, {}, {"猫", "好き"}}
+	if err := s.calculateTf(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.calculateIdf(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s.calculateTfidf()
+	if err := s.createSimilarityGraph(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if sim := s.similarity.At(0, 1); sim != 0 {
+		t.Errorf("similarity to a sentence without terms = %v, want 0", sim)
+	}
+	if sim := s.similarity.At(0, 2); sim < 0.999 {
+		t.Errorf("similarity of equal sentences = %v, want 1", sim)
+	}
+}
+
+func TestSimilarityOfEqualSentences(t *testing.T) {
+	base := similarityData(500)
+	n := len(base.wordsPerSentence)
+	s := &SummaryData{config: defaultConfig()}
+	s.wordsPerSentence = append(base.wordsPerSentence, base.wordsPerSentence...)
+	s.originalSentences = make([]string, 2*n)
+	s.buildVocabulary()
+	s.calculateVectors()
+	if err := s.createSimilarityGraph(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if sim := s.similarity.At(i, i+n); sim > 1 || sim < 0.999 {
+			t.Fatalf("similarity of equal sentences = %v, want 1 at most", sim)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package lexrankmmr
+
+import "context"
+
+// sumBasicRanker is SumBasic of Nenkova and Vanderwende. It repeatedly picks
+// the sentence with the highest average word probability, then squares the
+// probabilities of the words it contains to avoid redundancy.
+// Sentences are scored by the order in which they were picked.
+type sumBasicRanker struct{}
+
+func (r sumBasicRanker) Name() string {
+	return string(SumBasic)
+}
+
+func (r sumBasicRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	n := len(doc.Words)
+	counts, total := wordCounts(doc.Words)
+	probability := make(map[string]float64, len(counts))
+	for word, count := range counts {
+		probability[word] = float64(count) / float64(total)
+	}
+	picked := make([]bool, n)
+	order := make([]int, 0, n)
+	for len(order) < n {
+		if err := checkContext(ctx); err != nil {
+			return Ranking{}, err
+		}
+		best, bestScore := -1, -1.0
+		for i, words := range doc.Words {
+			if picked[i] {
+				continue
+			}
+			var score float64
+			for _, word := range words {
+				score += probability[word]
+			}
+			if len(words) > 0 {
+				score /= float64(len(words))
+			}
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked[best] = true
+		order = append(order, best)
+		squared := map[string]bool{}
+		for _, word := range doc.Words[best] {
+			if !squared[word] {
+				probability[word] *= probability[word]
+				squared[word] = true
+			}
+		}
+	}
+	return Ranking{Scores: scoresFromOrder(order, n)}, nil
+}
@@ -0,0 +1,98 @@
+package lexrankmmr
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ikawaha/kagome/tokenizer"
+)
+
+// Summarizer generates summaries.
+// It holds the dictionaries and the default options, and is safe for concurrent
+// use by multiple goroutines, so one Summarizer should be created and reused.
+type Summarizer struct {
+	mu     sync.RWMutex
+	config config
+}
+
+// Result is a summary generated by Summarizer.Summarize
+type Result struct {
+	LineLimitedSummary      []ScoredSentence
+	CharacterLimitedSummary []ScoredSentence
+	ByteLimitedSummary      []ScoredSentence
+	TokenLimitedSummary     []ScoredSentence
+	RatioLimitedSummary     []ScoredSentence
+	Algorithm               string
+	PageRank                *PageRankStats
+}
+
+// NewSummarizer return Summarizer which uses options as its defaults
+func NewSummarizer(options ...Option) (*Summarizer, error) {
+	s := &Summarizer{config: defaultConfig()}
+	if err := s.config.apply(options); err != nil {
+		return nil, err
+	}
+	// load the dictionary now rather than in the first call of Summarize
+	s.config.newTokenizer()
+	return s, nil
+}
+
+// Summarize generate summary of text.
+// options override the defaults of s for this call only.
+func (s *Summarizer) Summarize(ctx context.Context, text string, options ...Option) (Result, error) {
+	data, err := s.newData(options)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := data.summarize(ctx, text); err != nil {
+		return Result{}, err
+	}
+	return data.result(), nil
+}
+
+// SummarizeDocuments generate one summary of sources, such as articles on
+// the same event. The sentences of all sources are ranked together, and a
+// sentence nearly identical to a higher ranked one of another source is
+// dropped. options override the defaults of s for this call only.
+func (s *Summarizer) SummarizeDocuments(ctx context.Context, sources []Source, options ...Option) (Result, error) {
+	data, err := s.newData(options)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := data.summarizeSources(ctx, sources); err != nil {
+		return Result{}, err
+	}
+	return data.result(), nil
+}
+
+// newData returns SummaryData with the defaults of s overridden by options
+func (s *Summarizer) newData(options []Option) (*SummaryData, error) {
+	s.mu.RLock()
+	c := s.config
+	s.mu.RUnlock()
+	if err := c.apply(options); err != nil {
+		return nil, err
+	}
+	return &SummaryData{config: c, tokenizer: c.newTokenizer()}, nil
+}
+
+func (s *SummaryData) result() Result {
+	return Result{
+		LineLimitedSummary:      s.LineLimitedSummary,
+		CharacterLimitedSummary: s.CharacterLimitedSummary,
+		ByteLimitedSummary:      s.ByteLimitedSummary,
+		TokenLimitedSummary:     s.TokenLimitedSummary,
+		RatioLimitedSummary:     s.RatioLimitedSummary,
+		Algorithm:               s.Algorithm,
+		PageRank:                s.PageRank,
+	}
+}
+
+// SetUserDic replaces the user dictionary of s.
+// Calls of Summarize already running keep using the previous one.
+// The zero UserDic removes the user dictionary.
+func (s *Summarizer) SetUserDic(d tokenizer.UserDic) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config.userDic = &d
+}
@@ -0,0 +1,58 @@
+package lexrankmmr
+
+import (
+	"context"
+	"math"
+)
+
+// textRanker is TextRank of Mihalcea and Tarau. Two sentences are linked by
+// the number of words they share, normalized by the log of their lengths.
+type textRanker struct {
+	damping       float64
+	tolerance     float64
+	maxIterations int
+}
+
+func (r textRanker) Name() string {
+	return string(TextRank)
+}
+
+func (r textRanker) Rank(ctx context.Context, doc *Document) (Ranking, error) {
+	n := len(doc.Words)
+	sets := make([]map[string]bool, n)
+	for i, words := range doc.Words {
+		sets[i] = map[string]bool{}
+		for _, word := range words {
+			sets[i][word] = true
+		}
+	}
+	graph := newGraph(n)
+	for i := 0; i < n; i++ {
+		if err := checkContext(ctx); err != nil {
+			return Ranking{}, err
+		}
+		for j := i + 1; j < n; j++ {
+			overlap := 0
+			for word := range sets[i] {
+				if sets[j][word] {
+					overlap++
+				}
+			}
+			if overlap == 0 {
+				continue
+			}
+			denominator := math.Log(float64(len(doc.Words[i]))) + math.Log(float64(len(doc.Words[j])))
+			if denominator <= 0 {
+				denominator = 1
+			}
+			weight := float64(overlap) / denominator
+			graph.link(i, j, weight)
+			graph.link(j, i, weight)
+		}
+	}
+	ranks, stats, err := graph.rank(ctx, r.damping, r.tolerance, r.maxIterations, doc.Relevance)
+	if err != nil {
+		return Ranking{}, err
+	}
+	return Ranking{Scores: ranks, PageRank: &stats}, nil
+}
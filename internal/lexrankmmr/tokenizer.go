@@ -0,0 +1,102 @@
+package lexrankmmr
+
+import "github.com/ikawaha/kagome/tokenizer"
+
+// Dictionary is the name of a system dictionary built in kagome
+type Dictionary string
+
+// System dictionaries. The simple variants have no contents other than the
+// part of speech, so they use less memory but BaseForm has no effect.
+// kagome loads a dictionary when it is used for the first time, and the
+// full and simple variants of a dictionary share the one loaded first.
+const (
+	IPA          Dictionary = "ipa"
+	IPASimple    Dictionary = "ipa-simple"
+	UniDic       Dictionary = "uni"
+	UniDicSimple Dictionary = "uni-simple"
+)
+
+// Dictionaries lists the system dictionaries
+var Dictionaries = []Dictionary{IPA, IPASimple, UniDic, UniDicSimple}
+
+// Full returns the full variant of d. Dictionaries with the same full variant
+// share the one loaded first, so only one of them should be used.
+func (d Dictionary) Full() Dictionary {
+	switch d {
+	case IPASimple:
+		return IPA
+	case UniDicSimple:
+		return UniDic
+	}
+	return d
+}
+
+// load returns the dictionary, loading it if it is not loaded yet
+func (d Dictionary) load() tokenizer.Dic {
+	switch d {
+	case IPASimple:
+		return tokenizer.SysDicIPASimple()
+	case UniDic:
+		return tokenizer.SysDicUni()
+	case UniDicSimple:
+		return tokenizer.SysDicUniSimple()
+	}
+	return tokenizer.SysDicIPA()
+}
+
+// baseFormFeature returns the index of the base form in the features of d
+func (d Dictionary) baseFormFeature() int {
+	switch d {
+	case UniDic, UniDicSimple:
+		// 書字形基本形
+		return 10
+	}
+	// 基本形
+	return 6
+}
+
+// SystemDic set config.dictionary. It replaces the tokenizer given by Tokenizer.
+func SystemDic(d Dictionary) Option {
+	return func(args *config) error {
+		for _, dictionary := range Dictionaries {
+			if d == dictionary {
+				args.dictionary = d
+				args.tokenizer = nil
+				return nil
+			}
+		}
+		return &OptionError{Option: "dictionary", Err: ErrOutOfRange}
+	}
+}
+
+// Tokenizer set config.tokenizer, the kagome tokenizer used instead of the
+// one of the system dictionary. Its features must be laid out as SystemDic.
+func Tokenizer(t tokenizer.Tokenizer) Option {
+	return func(args *config) error {
+		args.tokenizer = &t
+		return nil
+	}
+}
+
+// UserDic set config.userDic, a user dictionary for domain terms.
+// It is used together with the system dictionary of the tokenizer.
+func UserDic(d tokenizer.UserDic) Option {
+	return func(args *config) error {
+		args.userDic = &d
+		return nil
+	}
+}
+
+// newTokenizer returns the tokenizer selected by c
+func (c *config) newTokenizer() tokenizer.Tokenizer {
+	var t tokenizer.Tokenizer
+	if c.tokenizer != nil {
+		t = *c.tokenizer
+	} else {
+		t = tokenizer.NewWithDic(c.dictionary.load())
+	}
+	if c.userDic != nil {
+		t.SetUserDic(*c.userDic)
+	}
+	return t
+}
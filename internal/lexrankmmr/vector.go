@@ -0,0 +1,125 @@
+package lexrankmmr
+
+import (
+	"math"
+	"sort"
+)
+
+// VectorModel selects how sentences are turned into vectors for similarity
+type VectorModel int
+
+const (
+	// VocabularyVector indexes TF-IDF scores by term id over the document vocabulary
+	VocabularyVector VectorModel = iota
+	// PositionalVector indexes TF-IDF scores by word position in each sentence.
+	// This is the behavior of earlier versions and is kept for comparison.
+	PositionalVector
+)
+
+// sparseVector is a term-id indexed vector. ids are sorted in ascending order.
+type sparseVector struct {
+	ids     []int
+	weights []float64
+	norm    float64
+}
+
+// buildVocabulary assigns a term id to every distinct word of the document
+func (s *SummaryData) buildVocabulary() {
+	s.vocabulary = newVocabulary(s.wordsPerSentence)
+}
+
+// calculateVectors creates a TF-IDF sparseVector for each sentence
+func (s *SummaryData) calculateVectors() {
+	s.vectors = tfidfVectors(s.wordsPerSentence, s.vocabulary, s.idf())
+}
+
+func newVocabulary(wordsPerSentence [][]string) map[string]int {
+	vocabulary := map[string]int{}
+	for _, words := range wordsPerSentence {
+		for _, word := range words {
+			if _, ok := vocabulary[word]; !ok {
+				vocabulary[word] = len(vocabulary)
+			}
+		}
+	}
+	return vocabulary
+}
+
+// tfidfVectors creates a TF-IDF sparseVector for each sentence.
+// TF is the count of the term in the sentence and IDF is given by idf.
+func tfidfVectors(wordsPerSentence [][]string, vocabulary map[string]int, idf idfFunc) []sparseVector {
+	words := make([]string, len(vocabulary))
+	for word, id := range vocabulary {
+		words[id] = word
+	}
+	termCounts := make([]map[int]int, len(wordsPerSentence))
+	df := make([]int, len(vocabulary))
+	for i, words := range wordsPerSentence {
+		termCounts[i] = map[int]int{}
+		for _, word := range words {
+			termCounts[i][vocabulary[word]]++
+		}
+		for id := range termCounts[i] {
+			df[id]++
+		}
+	}
+	n := len(wordsPerSentence)
+	vectors := make([]sparseVector, len(wordsPerSentence))
+	for i, counts := range termCounts {
+		v := sparseVector{
+			ids:     make([]int, 0, len(counts)),
+			weights: make([]float64, 0, len(counts)),
+		}
+		for id := range counts {
+			v.ids = append(v.ids, id)
+		}
+		sort.Ints(v.ids)
+		var sum float64
+		for _, id := range v.ids {
+			w := float64(counts[id]) * idf(words[id], df[id], n)
+			v.weights = append(v.weights, w)
+			sum += w * w
+		}
+		v.norm = math.Sqrt(sum)
+		vectors[i] = v
+	}
+	return vectors
+}
+
+// dotDense returns the dot product of a and the dense vector d indexed by term id
+func (a sparseVector) dotDense(d []float64) float64 {
+	var dot float64
+	for i, id := range a.ids {
+		dot += a.weights[i] * d[id]
+	}
+	return dot
+}
+
+// addTo adds scale*a to the dense vector d indexed by term id
+func (a sparseVector) addTo(d []float64, scale float64) {
+	for i, id := range a.ids {
+		d[id] += scale * a.weights[i]
+	}
+}
+
+// cosine returns the cosine similarity of a and b over the shared term space.
+// Empty vectors share no terms with anything, so their similarity is 0.
+func (a sparseVector) cosine(b sparseVector) float64 {
+	if a.norm == 0 || b.norm == 0 {
+		return 0
+	}
+	var dot float64
+	for i, j := 0, 0; i < len(a.ids) && j < len(b.ids); {
+		switch {
+		case a.ids[i] == b.ids[j]:
+			dot += a.weights[i] * b.weights[j]
+			i++
+			j++
+		case a.ids[i] < b.ids[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return dot / (a.norm * b.norm)
+}
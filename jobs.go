@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+const jobsPath = apiPrefix + "/jobs"
+
+// Job statuses
+const (
+	jobQueued   = "queued"
+	jobRunning  = "running"
+	jobDone     = "done"
+	jobFailed   = "failed"
+	jobCanceled = "canceled"
+)
+
+// job is a summarization running in the background
+type job struct {
+	ID        string               `json:"id"`
+	Status    string               `json:"status"`
+	Progress  *lexrankmmr.Progress `json:"progress,omitempty"`
+	Result    *lexrankmmr.Result   `json:"result,omitempty"`
+	Error     *errorBody           `json:"error,omitempty"`
+	RequestID string               `json:"requestId"`
+	CreatedAt time.Time            `json:"createdAt"`
+	UpdatedAt time.Time            `json:"updatedAt"`
+	// Request is kept until the job finishes, to run it again after a restart.
+	// It is not returned to clients.
+	Request *summarizeRequest `json:"request,omitempty"`
+}
+
+func (j *job) finished() bool {
+	return j.Status == jobDone || j.Status == jobFailed || j.Status == jobCanceled
+}
+
+// view returns j as returned to clients
+func (j job) view() job {
+	j.Request = nil
+	return j
+}
+
+// newJobID returns a random job id
+func newJobID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// isJobID reports whether id is in the format of newJobID
+func isJobID(id string) bool {
+	b, err := hex.DecodeString(id)
+	return err == nil && len(b) == 16
+}
+
+// jobManager runs jobs in the background, at most workers at the same time.
+// Finished jobs are removed from store retention after they finished.
+type jobManager struct {
+	server    *server
+	store     jobStore
+	slots     chan struct{}
+	timeout   time.Duration
+	retention time.Duration
+
+	// mu serializes the updates of jobs in store
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+
+	// progress of the running jobs is kept apart from store, so that
+	// reporting it does not rewrite the jobs
+	progressMu sync.Mutex
+	progress   map[string]lexrankmmr.Progress
+}
+
+func newJobManager(s *server, store jobStore, workers int, timeout, retention time.Duration) *jobManager {
+	return &jobManager{
+		server:    s,
+		store:     store,
+		slots:     make(chan struct{}, workers),
+		timeout:   timeout,
+		retention: retention,
+		cancels:   map[string]context.CancelFunc{},
+		progress:  map[string]lexrankmmr.Progress{},
+	}
+}
+
+// get returns the job of id with its progress if it is running
+func (m *jobManager) get(id string) (*job, error) {
+	j, err := m.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	j.Progress = nil
+	if j.Status == jobRunning {
+		m.progressMu.Lock()
+		if p, ok := m.progress[id]; ok {
+			j.Progress = &p
+		}
+		m.progressMu.Unlock()
+	}
+	return j, nil
+}
+
+func (m *jobManager) setProgress(id string, p lexrankmmr.Progress) {
+	m.progressMu.Lock()
+	m.progress[id] = p
+	m.progressMu.Unlock()
+}
+
+func (m *jobManager) clearProgress(id string) {
+	m.progressMu.Lock()
+	delete(m.progress, id)
+	m.progressMu.Unlock()
+}
+
+// jobSweepInterval returns how often finished jobs are looked for to be
+// removed after retention
+func jobSweepInterval(retention time.Duration) time.Duration {
+	if interval := retention / 10; interval < time.Hour {
+		return interval
+	}
+	return time.Hour
+}
+
+// sweepEvery removes the expired jobs every interval
+func (m *jobManager) sweepEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if err := m.sweep(now); err != nil {
+			log.Printf("Cannot remove expired jobs: %v", err)
+		}
+	}
+}
+
+// sweep removes the jobs which finished retention before now. Finished jobs
+// are not updated anymore, so they can be removed without m.mu.
+func (m *jobManager) sweep(now time.Time) error {
+	jobs, err := m.store.List()
+	if err != nil {
+		return err
+	}
+	expiry := now.Add(-m.retention)
+	for _, j := range jobs {
+		if j.finished() && j.UpdatedAt.Before(expiry) {
+			if err := m.store.Delete(j.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resume queues again the jobs which had not finished before a restart
+func (m *jobManager) resume() error {
+	jobs, err := m.store.List()
+	if err != nil {
+		return err
+	}
+	for _, j := range jobs {
+		if j.finished() || j.Request == nil {
+			continue
+		}
+		j.Status = jobQueued
+		j.Progress = nil
+		if err := m.store.Put(j); err != nil {
+			return err
+		}
+		m.start(j)
+	}
+	return nil
+}
+
+// submit stores a new job summarizing req and starts it
+func (m *jobManager) submit(req summarizeRequest, requestID string) (*job, error) {
+	now := time.Now().UTC()
+	j := &job{
+		ID:        newJobID(),
+		Status:    jobQueued,
+		RequestID: requestID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Request:   &req,
+	}
+	if err := m.store.Put(j); err != nil {
+		return nil, err
+	}
+	m.start(j)
+	return j, nil
+}
+
+func (m *jobManager) start(j *job) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	m.cancels[j.ID] = cancel
+	m.mu.Unlock()
+	go m.run(ctx, j.ID, *j.Request)
+}
+
+// run waits for a free worker and summarizes req as the job of id
+func (m *jobManager) run(ctx context.Context, id string, req summarizeRequest) {
+	defer func() {
+		m.mu.Lock()
+		if cancel, ok := m.cancels[id]; ok {
+			cancel()
+			delete(m.cancels, id)
+		}
+		m.mu.Unlock()
+		m.clearProgress(id)
+	}()
+	select {
+	case m.slots <- struct{}{}:
+	case <-ctx.Done():
+		return
+	}
+	defer func() { <-m.slots }()
+
+	started := m.update(id, func(j *job) bool {
+		if j.Status != jobQueued {
+			return false
+		}
+		j.Status = jobRunning
+		return true
+	})
+	if !started {
+		return
+	}
+
+	var summary lexrankmmr.Result
+	options, err := m.server.options(req)
+	if err == nil {
+		options = append(options, lexrankmmr.OnProgress(func(p lexrankmmr.Progress) {
+			m.setProgress(id, p)
+		}))
+		ctx, cancel := context.WithTimeout(ctx, m.timeout)
+		defer cancel()
+		summary, err = m.server.summarizer.Summarize(ctx, req.Text, options...)
+	}
+	m.update(id, func(j *job) bool {
+		if j.Status != jobRunning {
+			// canceled while running
+			return false
+		}
+		if err != nil {
+			j.Status = jobFailed
+			j.Error = newErrorBody(err, j.RequestID)
+		} else {
+			j.Status = jobDone
+			j.Result = &summary
+		}
+		j.Request = nil
+		return true
+	})
+}
+
+// update applies f to the job of id and stores it when f returns true
+func (m *jobManager) update(id string, f func(*job) bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, err := m.store.Get(id)
+	if err != nil || !f(j) {
+		return false
+	}
+	j.UpdatedAt = time.Now().UTC()
+	return m.store.Put(j) == nil
+}
+
+// cancel stops the job of id if it has not finished, or removes it if it has.
+// removed reports which was done.
+func (m *jobManager) cancel(id string) (j *job, removed bool, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, err = m.store.Get(id)
+	if err != nil {
+		return nil, false, err
+	}
+	if j.finished() {
+		return j, true, m.store.Delete(id)
+	}
+	j.Status = jobCanceled
+	j.Request = nil
+	j.UpdatedAt = time.Now().UTC()
+	if err := m.store.Put(j); err != nil {
+		return nil, false, err
+	}
+	if cancel, ok := m.cancels[id]; ok {
+		cancel()
+	}
+	return j, false, nil
+}
+
+func jobNotFound() *apiError {
+	return &apiError{
+		status:  http.StatusNotFound,
+		code:    codeNotFound,
+		message: "job not found",
+	}
+}
+
+// jobsHandler creates a job from a request in the same format as POST /v1/summarize
+// and returns it with 202 Accepted
+func (s *server) jobsHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	req, err := parseRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if req.Text == "" {
+		writeError(w, lexrankmmr.ErrEmptyInput)
+		return
+	}
+	// reject invalid options now rather than in the job
+	if _, err := s.options(req); err != nil {
+		writeError(w, err)
+		return
+	}
+	j, err := s.jobs.submit(req, w.Header().Get(requestIDHeader))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", jobsPath+"/"+j.ID)
+	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, j.view())
+}
+
+// jobHandler returns the job of the id in the path with GET, and cancels it
+// with DELETE. DELETE of a finished job removes it.
+func (s *server) jobHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, DELETE")
+	id := strings.TrimPrefix(r.URL.Path, jobsPath+"/")
+
+	switch r.Method {
+	case http.MethodGet:
+		j, err := s.jobs.get(id)
+		if errors.Is(err, errJobNotFound) {
+			err = jobNotFound()
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, j.view())
+	case http.MethodDelete:
+		j, removed, err := s.jobs.cancel(id)
+		if errors.Is(err, errJobNotFound) {
+			err = jobNotFound()
+		}
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if removed {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, j.view())
+	}
+}
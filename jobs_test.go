@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobsRejectInvalidOptions(t *testing.T) {
+	tests := []struct {
+		body  string
+		field string
+	}{
+		{`{"text": "今日は晴れ。", "lambda": 5}`, "lambda"},
+		{`{"text": "今日は晴れ。", "threshold": 2}`, "threshold"},
+		{`{"text": "今日は晴れ。", "maxLines": -1}`, "maxLines"},
+		{`{"text": "今日は晴れ。", "maxIterations": 0}`, "maxIterations"},
+	}
+	s := newServer(nil, config{})
+	store := newMemoryStore()
+	s.jobs = newJobManager(s, store, 1, time.Minute, time.Hour)
+	handler := s.router()
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, jobsPath, strings.NewReader(tt.body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: status = %d, want %d", tt.body, w.Code, http.StatusUnprocessableEntity)
+			continue
+		}
+		if got := decodeError(t, w); got.Code != codeOutOfRange || got.Field != tt.field {
+			t.Errorf("%s: error = %+v, want %s of %s", tt.body, got, codeOutOfRange, tt.field)
+		}
+	}
+	if jobs, _ := store.List(); len(jobs) != 0 {
+		t.Errorf("%d jobs were created for invalid requests", len(jobs))
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var errJobNotFound = errors.New("job not found")
+
+// jobStore keeps jobs. Implementations must be safe for concurrent use.
+type jobStore interface {
+	// Put creates or replaces j
+	Put(j *job) error
+	// Get returns the job of id, or errJobNotFound
+	Get(id string) (*job, error)
+	// Delete removes the job of id
+	Delete(id string) error
+	// List returns all jobs
+	List() ([]*job, error)
+}
+
+// memoryStore keeps jobs in memory. They are lost on restart.
+type memoryStore struct {
+	mu   sync.RWMutex
+	jobs map[string]job
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{jobs: map[string]job{}}
+}
+
+func (m *memoryStore) Put(j *job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[j.ID] = *j
+	return nil
+}
+
+func (m *memoryStore) Get(id string) (*job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, errJobNotFound
+	}
+	return &j, nil
+}
+
+func (m *memoryStore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, id)
+	return nil
+}
+
+func (m *memoryStore) List() ([]*job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	jobs := make([]*job, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		j := j
+		jobs = append(jobs, &j)
+	}
+	return jobs, nil
+}
+
+// diskStore keeps each job as a JSON file in a directory, so that jobs
+// survive restarts. Files are replaced by rename, so a crash never leaves
+// a partially written job.
+type diskStore struct {
+	dir string
+}
+
+const jobFileExt = ".json"
+
+func newDiskStore(dir string) (*diskStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &diskStore{dir: dir}, nil
+}
+
+func (d *diskStore) path(id string) string {
+	return filepath.Join(d.dir, id+jobFileExt)
+}
+
+func (d *diskStore) Put(j *job) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(d.dir, j.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), d.path(j.ID))
+}
+
+func (d *diskStore) Get(id string) (*job, error) {
+	if !isJobID(id) {
+		return nil, errJobNotFound
+	}
+	data, err := os.ReadFile(d.path(id))
+	if os.IsNotExist(err) {
+		return nil, errJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	j := &job{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (d *diskStore) Delete(id string) error {
+	if !isJobID(id) {
+		return nil
+	}
+	err := os.Remove(d.path(id))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (d *diskStore) List() ([]*job, error) {
+	files, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*job
+	for _, f := range files {
+		if !strings.HasSuffix(f.Name(), jobFileExt) {
+			continue
+		}
+		j, err := d.Get(strings.TrimSuffix(f.Name(), jobFileExt))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+// testStores returns a store of each implementation
+func testStores(t *testing.T) map[string]jobStore {
+	t.Helper()
+	disk, err := newDiskStore(filepath.Join(t.TempDir(), "jobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return map[string]jobStore{"memory": newMemoryStore(), "disk": disk}
+}
+
+func testJob(status string, updatedAt time.Time) *job {
+	return &job{
+		ID:        newJobID(),
+		Status:    status,
+		CreatedAt: updatedAt,
+		UpdatedAt: updatedAt,
+	}
+}
+
+func TestJobStore(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			now := time.Now().UTC().Truncate(time.Second)
+			a := testJob(jobQueued, now)
+			a.Request = &summarizeRequest{Text: "今日は晴れ。"}
+			b := testJob(jobDone, now)
+			for _, j := range []*job{a, b} {
+				if err := store.Put(j); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := store.Get(a.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != jobQueued || got.Request == nil || got.Request.Text != a.Request.Text || !got.UpdatedAt.Equal(now) {
+				t.Errorf("Get = %+v, want %+v", got, a)
+			}
+			// the returned job is a copy
+			got.Status = jobRunning
+			if again, _ := store.Get(a.ID); again.Status != jobQueued {
+				t.Errorf("modifying the returned job changed the store")
+			}
+
+			a.Status = jobRunning
+			if err := store.Put(a); err != nil {
+				t.Fatal(err)
+			}
+			if got, _ := store.Get(a.ID); got.Status != jobRunning {
+				t.Errorf("status after Put = %q, want %q", got.Status, jobRunning)
+			}
+
+			jobs, err := store.List()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids, want []string
+			for _, j := range jobs {
+				ids = append(ids, j.ID)
+			}
+			want = []string{a.ID, b.ID}
+			sort.Strings(ids)
+			sort.Strings(want)
+			if len(ids) != 2 || ids[0] != want[0] || ids[1] != want[1] {
+				t.Errorf("List = %v, want %v", ids, want)
+			}
+
+			if err := store.Delete(b.ID); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := store.Get(b.ID); !errors.Is(err, errJobNotFound) {
+				t.Errorf("Get of a deleted job: %v, want errJobNotFound", err)
+			}
+			if err := store.Delete(b.ID); err != nil {
+				t.Errorf("Delete of a deleted job: %v", err)
+			}
+			if _, err := store.Get(newJobID()); !errors.Is(err, errJobNotFound) {
+				t.Errorf("Get of an unknown job: %v, want errJobNotFound", err)
+			}
+		})
+	}
+}
+
+func TestDiskStoreRejectsPaths(t *testing.T) {
+	dir := t.TempDir()
+	store, err := newDiskStore(filepath.Join(dir, "jobs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(dir, "secret"+jobFileExt)
+	if err := os.WriteFile(secret, []byte(`{"id":"secret"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"../secret", "..%2Fsecret", "", "abc"} {
+		if _, err := store.Get(id); !errors.Is(err, errJobNotFound) {
+			t.Errorf("Get(%q): %v, want errJobNotFound", id, err)
+		}
+		if err := store.Delete(id); err != nil {
+			t.Errorf("Delete(%q): %v", id, err)
+		}
+	}
+	if _, err := os.Stat(secret); err != nil {
+		t.Errorf("a file outside the store was removed: %v", err)
+	}
+}
+
+func TestJobSweep(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			m := newJobManager(&server{}, store, 1, time.Minute, time.Hour)
+			now := time.Now().UTC()
+			old := now.Add(-2 * time.Hour)
+			expired := []*job{testJob(jobDone, old), testJob(jobFailed, old), testJob(jobCanceled, old)}
+			kept := []*job{testJob(jobDone, now), testJob(jobQueued, old), testJob(jobRunning, old)}
+			for _, j := range append(expired, kept...) {
+				if err := store.Put(j); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := m.sweep(now); err != nil {
+				t.Fatal(err)
+			}
+			for _, j := range expired {
+				if _, err := store.Get(j.ID); !errors.Is(err, errJobNotFound) {
+					t.Errorf("%s job finished before the retention was kept", j.Status)
+				}
+			}
+			for _, j := range kept {
+				if _, err := store.Get(j.ID); err != nil {
+					t.Errorf("%s job updated at %v was removed: %v", j.Status, j.UpdatedAt, err)
+				}
+			}
+		})
+	}
+}
+
+func TestJobProgress(t *testing.T) {
+	store := newMemoryStore()
+	m := newJobManager(&server{}, store, 1, time.Minute, time.Hour)
+	running := testJob(jobRunning, time.Now().UTC())
+	done := testJob(jobDone, time.Now().UTC())
+	for _, j := range []*job{running, done} {
+		if err := store.Put(j); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p := lexrankmmr.Progress{Stage: lexrankmmr.StageSimilarity, Sentences: 10, Percent: 50}
+	m.setProgress(running.ID, p)
+	m.setProgress(done.ID, p)
+
+	got, err := m.get(running.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Progress == nil || *got.Progress != p {
+		t.Errorf("progress of a running job = %v, want %v", got.Progress, p)
+	}
+	if stored, _ := store.Get(running.ID); stored.Progress != nil {
+		t.Errorf("progress was stored: %v", stored.Progress)
+	}
+	if got, _ := m.get(done.ID); got.Progress != nil {
+		t.Errorf("progress of a finished job = %v, want none", got.Progress)
+	}
+
+	m.clearProgress(running.ID)
+	if got, _ := m.get(running.ID); got.Progress != nil {
+		t.Errorf("progress after clearProgress = %v, want none", got.Progress)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+const defaultMaxKeywords = 10
+
+// keywordsRequest contains parameters for keyword extraction
+type keywordsRequest struct {
+	Text        string `json:"text"`
+	MaxKeywords int    `json:"maxKeywords"`
+	filterRequest
+}
+
+// keywordsResponse is the response of keywordsHandler
+type keywordsResponse struct {
+	Keywords []lexrankmmr.Keyword `json:"keywords"`
+}
+
+func newKeywordsRequest() keywordsRequest {
+	return keywordsRequest{
+		MaxKeywords: defaultMaxKeywords,
+	}
+}
+
+// parseKeywordsRequest read keywordsRequest from JSON body or form-data
+func parseKeywordsRequest(r *http.Request) (keywordsRequest, error) {
+	req := newKeywordsRequest()
+	err := decodeBody(r, &req)
+	return req, err
+}
+
+// decodeValues read the fields of req given in values
+func (req *keywordsRequest) decodeValues(values url.Values) error {
+	req.Text = values.Get("text")
+	if v := values.Get("maxKeywords"); v != "" {
+		maxKeywords, err := strconv.Atoi(v)
+		if err != nil {
+			return &fieldError{field: "maxKeywords", err: err}
+		}
+		req.MaxKeywords = maxKeywords
+	}
+	return req.filterRequest.decodeValues(values)
+}
+
+// keywordsHandler returns the terms of the text with the highest TF-IDF weight
+func (s *server) keywordsHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	req, err := parseKeywordsRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	options, err := s.filterOptions(req.filterRequest)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	keywords, err := s.summarizer.Keywords(ctx, req.Text, req.MaxKeywords, options...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, keywordsResponse{Keywords: keywords})
+}
@@ -5,10 +5,33 @@ import (
 	"log"
 	"net/http"
 	"os"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
 )
 
 func main() {
-	http.HandleFunc("/", handler)
+	c, err := loadConfig()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	options, err := c.summarizerOptions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	summarizer, err := lexrankmmr.NewSummarizer(options...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := newServer(summarizer, c)
+	store, err := c.jobStore()
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv.jobs = newJobManager(srv, store, c.jobWorkers, c.jobTimeout, c.jobRetention)
+	if err := srv.jobs.resume(); err != nil {
+		log.Fatal(err)
+	}
+	go srv.jobs.sweepEvery(jobSweepInterval(c.jobRetention))
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -17,5 +40,5 @@ func main() {
 	}
 
 	log.Printf("Listening on port %s", port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", port), nil))
+	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", port), srv.router()))
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+// multiRequest contains parameters for summary of several documents.
+// The options are those of summarizeRequest, and text must be empty.
+type multiRequest struct {
+	summarizeRequest
+	Documents          []documentRequest `json:"documents"`
+	DuplicateThreshold float64           `json:"duplicateThreshold"`
+}
+
+// documentRequest is a document of multiRequest
+type documentRequest struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+func newMultiRequest() multiRequest {
+	return multiRequest{
+		summarizeRequest:   newSummarizeRequest(),
+		DuplicateThreshold: defaultDuplicate,
+	}
+}
+
+// parseMultiRequest read multiRequest from JSON body
+func parseMultiRequest(r *http.Request) (multiRequest, error) {
+	req := newMultiRequest()
+	if !isJSON(r) {
+		return req, &apiError{
+			status:  http.StatusUnsupportedMediaType,
+			code:    codeUnsupportedType,
+			message: "request body must be application/json",
+		}
+	}
+	err := decodeJSON(r.Body, &req)
+	return req, err
+}
+
+// sources returns the documents of req as lexrankmmr.Source.
+// Documents without id are identified by their index.
+func (req *multiRequest) sources() ([]lexrankmmr.Source, error) {
+	if req.Text != "" {
+		return nil, invalidValue("text", "text is not used, send documents instead")
+	}
+	if len(req.Documents) == 0 {
+		return nil, &apiError{
+			status:  http.StatusUnprocessableEntity,
+			code:    codeEmptyText,
+			message: "documents must not be empty",
+			field:   "documents",
+		}
+	}
+	sources := make([]lexrankmmr.Source, len(req.Documents))
+	ids := map[string]bool{}
+	for i, document := range req.Documents {
+		id := document.ID
+		if id == "" {
+			id = strconv.Itoa(i)
+		}
+		if ids[id] {
+			return nil, invalidValue("documents", "document id "+strconv.Quote(id)+" is duplicated")
+		}
+		ids[id] = true
+		sources[i] = lexrankmmr.Source{ID: id, Text: document.Text}
+	}
+	return sources, nil
+}
+
+// multiHandler summarizes several documents on the same topic into one summary
+func (s *server) multiHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	req, err := parseMultiRequest(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	sources, err := req.sources()
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	options, err := s.options(req.summarizeRequest)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	options = append(options, lexrankmmr.DuplicateThreshold(req.DuplicateThreshold))
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	summary, err := s.summarizer.SummarizeDocuments(ctx, sources, options...)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, summary)
+}
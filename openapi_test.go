@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+// openAPI is the part of openapi.json checked against the code
+type openAPI struct {
+	Paths      map[string]map[string]json.RawMessage `json:"paths"`
+	Components struct {
+		Schemas map[string]*schema `json:"schemas"`
+	} `json:"components"`
+}
+
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Format     string             `json:"format"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	AllOf      []*schema          `json:"allOf"`
+	Enum       []string           `json:"enum"`
+	Default    interface{}        `json:"default"`
+}
+
+// schemaTypes maps the schemas of openapi.json to the types they describe
+var schemaTypes = map[string]reflect.Type{
+	"TermFilters":      reflect.TypeOf(filterRequest{}),
+	"SummarizeRequest": reflect.TypeOf(summarizeRequest{}),
+	"MultiRequest":     reflect.TypeOf(multiRequest{}),
+	"Document":         reflect.TypeOf(documentRequest{}),
+	"BatchItem":        reflect.TypeOf(batchItem{}),
+	"BatchResult":      reflect.TypeOf(batchResult{}),
+	"KeywordsRequest":  reflect.TypeOf(keywordsRequest{}),
+	"KeywordsResponse": reflect.TypeOf(keywordsResponse{}),
+	"Keyword":          reflect.TypeOf(lexrankmmr.Keyword{}),
+	"Result":           reflect.TypeOf(lexrankmmr.Result{}),
+	"ScoredSentence":   reflect.TypeOf(lexrankmmr.ScoredSentence{}),
+	"PageRankStats":    reflect.TypeOf(lexrankmmr.PageRankStats{}),
+	"Job":              reflect.TypeOf(job{}),
+	"Progress":         reflect.TypeOf(lexrankmmr.Progress{}),
+	"ErrorResponse":    reflect.TypeOf(errorResponse{}),
+	"Error":            reflect.TypeOf(errorBody{}),
+}
+
+// hiddenFields are fields of the types which are not returned to clients
+var hiddenFields = map[string]bool{
+	"Job.request": true,
+}
+
+func loadOpenAPI(t *testing.T) *openAPI {
+	t.Helper()
+	spec := &openAPI{}
+	if err := json.Unmarshal(openAPISpec, spec); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+	return spec
+}
+
+// resolve returns the schema referred by s, or s
+func (spec *openAPI) resolve(t *testing.T, s *schema) (*schema, string) {
+	t.Helper()
+	if s.Ref == "" {
+		return s, ""
+	}
+	name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+	resolved, ok := spec.Components.Schemas[name]
+	if !ok {
+		t.Fatalf("%s is not defined", s.Ref)
+	}
+	return resolved, name
+}
+
+// properties returns the properties of s including those of allOf
+func (spec *openAPI) properties(t *testing.T, s *schema) map[string]*schema {
+	t.Helper()
+	properties := map[string]*schema{}
+	s, _ = spec.resolve(t, s)
+	for _, sub := range s.AllOf {
+		for name, p := range spec.properties(t, sub) {
+			properties[name] = p
+		}
+	}
+	for name, p := range s.Properties {
+		properties[name] = p
+	}
+	return properties
+}
+
+// jsonFields returns the fields of struct type typ by their JSON name,
+// including the fields of embedded structs
+func jsonFields(typ reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		tag := f.Tag.Get("json")
+		if f.Anonymous && tag == "" {
+			for name, t := range jsonFields(f.Type) {
+				fields[name] = t
+			}
+			continue
+		}
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	spec := loadOpenAPI(t)
+	for name := range spec.Components.Schemas {
+		if _, ok := schemaTypes[name]; !ok {
+			t.Errorf("schema %s does not describe a type", name)
+		}
+	}
+	for name, typ := range schemaTypes {
+		s, ok := spec.Components.Schemas[name]
+		if !ok {
+			t.Errorf("schema %s of %v is missing", name, typ)
+			continue
+		}
+		properties := spec.properties(t, s)
+		fields := jsonFields(typ)
+		for field, fieldType := range fields {
+			if hiddenFields[name+"."+field] {
+				continue
+			}
+			p, ok := properties[field]
+			if !ok {
+				t.Errorf("%s.%s is missing", name, field)
+				continue
+			}
+			spec.checkType(t, name+"."+field, fieldType, p)
+		}
+		for field := range properties {
+			if _, ok := fields[field]; !ok {
+				t.Errorf("%s.%s is not a field of %v", name, field, typ)
+			}
+		}
+	}
+}
+
+// checkType checks that s describes the JSON of typ
+func (spec *openAPI) checkType(t *testing.T, path string, typ reflect.Type, s *schema) {
+	t.Helper()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if len(s.AllOf) == 1 {
+		s = s.AllOf[0]
+	}
+	if typ == reflect.TypeOf(time.Time{}) {
+		if s.Type != "string" || s.Format != "date-time" {
+			t.Errorf("%s must be a date-time string", path)
+		}
+		return
+	}
+	if typ.Kind() == reflect.Struct {
+		_, name := spec.resolve(t, s)
+		if schemaTypes[name] != typ {
+			t.Errorf("%s must refer to the schema of %v", path, typ)
+		}
+		return
+	}
+
+	var want string
+	switch typ.Kind() {
+	case reflect.String:
+		want = "string"
+	case reflect.Bool:
+		want = "boolean"
+	case reflect.Int:
+		want = "integer"
+	case reflect.Float64:
+		want = "number"
+	case reflect.Slice:
+		want = "array"
+	default:
+		t.Fatalf("%s: %v is not supported", path, typ)
+	}
+	if s.Type != want {
+		t.Errorf("%s is %q, want %q", path, s.Type, want)
+		return
+	}
+	if want == "array" {
+		if s.Items == nil {
+			t.Errorf("%s has no items", path)
+			return
+		}
+		spec.checkType(t, path+"[]", typ.Elem(), s.Items)
+	}
+}
+
+func TestOpenAPIDefaults(t *testing.T) {
+	spec := loadOpenAPI(t)
+	tests := []struct {
+		schema string
+		want   interface{}
+	}{
+		{"SummarizeRequest", newSummarizeRequest()},
+		{"MultiRequest", newMultiRequest()},
+		{"KeywordsRequest", newKeywordsRequest()},
+	}
+	for _, tt := range tests {
+		defaults := map[string]interface{}{}
+		for name, p := range spec.properties(t, spec.Components.Schemas[tt.schema]) {
+			if p.Default != nil {
+				defaults[name] = p.Default
+			}
+		}
+		data, err := json.Marshal(defaults)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := reflect.New(reflect.TypeOf(tt.want))
+		if err := json.Unmarshal(data, got.Interface()); err != nil {
+			t.Fatalf("defaults of %s: %v", tt.schema, err)
+		}
+		if !reflect.DeepEqual(got.Elem().Interface(), tt.want) {
+			t.Errorf("defaults of %s are %s, want %+v", tt.schema, data, tt.want)
+		}
+	}
+}
+
+func TestOpenAPIEnums(t *testing.T) {
+	spec := loadOpenAPI(t)
+	properties := spec.properties(t, spec.Components.Schemas["SummarizeRequest"])
+
+	var algorithms []string
+	for _, algorithm := range lexrankmmr.Algorithms {
+		algorithms = append(algorithms, string(algorithm))
+	}
+	tests := []struct {
+		field string
+		want  []string
+	}{
+		{"algorithm", algorithms},
+		{"mode", mapKeys(modes)},
+		{"vector", mapKeys(vectorModels)},
+		{"selection", mapKeys(selections)},
+	}
+	for _, tt := range tests {
+		got := append([]string{}, properties[tt.field].Enum...)
+		sort.Strings(got)
+		sort.Strings(tt.want)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("enum of %s is %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+// mapKeys returns the keys of m, a map with string keys
+func mapKeys(m interface{}) []string {
+	var keys []string
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	return keys
+}
+
+func TestOpenAPIPaths(t *testing.T) {
+	spec := loadOpenAPI(t)
+	routes := (&server{}).routes()
+	paths := map[string]bool{}
+	for _, rt := range routes {
+		paths[rt.path] = true
+		operations, ok := spec.Paths[rt.path]
+		if !ok {
+			t.Errorf("path %s is missing", rt.path)
+			continue
+		}
+		var got, want []string
+		for method := range operations {
+			if method != "parameters" {
+				got = append(got, strings.ToUpper(method))
+			}
+		}
+		want = append(want, rt.methods...)
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("methods of %s are %v, want %v", rt.path, got, want)
+		}
+	}
+	for path := range spec.Paths {
+		if !paths[path] {
+			t.Errorf("path %s is not routed", path)
+		}
+	}
+}
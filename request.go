@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ramenjuniti/summary-generator-api/internal/lexrankmmr"
+)
+
+const (
+	maxBodyBytes  = 10 << 20
+	maxFormMemory = 1 << 20
+)
+
+var (
+	errMultipleJSONValues = errors.New("request body must contain a single JSON object")
+	errEmptyBody          = errors.New("request body must not be empty")
+)
+
+// summarizeRequest contains parameters for summary
+type summarizeRequest struct {
+	Text                string  `json:"text"`
+	Query               string  `json:"query"`
+	MaxLines            int     `json:"maxLines"`
+	MaxCharacters       int     `json:"maxCharacters"`
+	MaxBytes            int     `json:"maxBytes"`
+	MaxTokens           int     `json:"maxTokens"`
+	MaxRatio            float64 `json:"maxRatio"`
+	Threshold           float64 `json:"threshold"`
+	Tolerance           float64 `json:"tolerance"`
+	Damping             float64 `json:"damping"`
+	Lambda              float64 `json:"lambda"`
+	RedundancyThreshold float64 `json:"redundancyThreshold"`
+	MaxIterations       int     `json:"maxIterations"`
+	Algorithm           string  `json:"algorithm"`
+	Mode                string  `json:"mode"`
+	Vector              string  `json:"vector"`
+	Selection           string  `json:"selection"`
+	filterRequest
+}
+
+// filterRequest contains parameters for the tokenization and the term filters
+type filterRequest struct {
+	PartsOfSpeech []string `json:"partsOfSpeech"`
+	Stopwords     []string `json:"stopwords"`
+	BaseForm      *bool    `json:"baseForm"`
+	Dictionary    string   `json:"dictionary"`
+}
+
+func newSummarizeRequest() summarizeRequest {
+	return summarizeRequest{
+		MaxLines:            defaultMaxLines,
+		MaxCharacters:       defaultMaxCharacters,
+		Threshold:           defaultThreshold,
+		Tolerance:           defaultTolerance,
+		Damping:             defaultDamping,
+		Lambda:              defaultLambda,
+		RedundancyThreshold: defaultRedundancy,
+		MaxIterations:       defaultMaxIterations,
+		Algorithm:           defaultAlgorithm,
+		Mode:                defaultMode,
+		Vector:              defaultVector,
+		Selection:           defaultSelection,
+	}
+}
+
+// clone returns a copy of req which shares no memory with it
+func (req summarizeRequest) clone() summarizeRequest {
+	req.filterRequest = req.filterRequest.clone()
+	return req
+}
+
+// clone returns a copy of req which shares no memory with it
+func (req filterRequest) clone() filterRequest {
+	req.PartsOfSpeech = append([]string(nil), req.PartsOfSpeech...)
+	req.Stopwords = append([]string(nil), req.Stopwords...)
+	if req.BaseForm != nil {
+		baseForm := *req.BaseForm
+		req.BaseForm = &baseForm
+	}
+	return req
+}
+
+// parseRequest read summarizeRequest from JSON body or form-data
+func parseRequest(r *http.Request) (summarizeRequest, error) {
+	req := newSummarizeRequest()
+	err := decodeBody(r, &req)
+	return req, err
+}
+
+// formRequest is a request which can also be sent as form-data
+type formRequest interface {
+	decodeValues(values url.Values) error
+}
+
+// decodeBody decodes the JSON or form-data body of r into req
+func decodeBody(r *http.Request, req formRequest) error {
+	if isJSON(r) {
+		return decodeJSON(r.Body, req)
+	}
+	if err := r.ParseMultipartForm(maxFormMemory); err != nil && err != http.ErrNotMultipart {
+		return &formError{err: err}
+	}
+	return req.decodeValues(r.Form)
+}
+
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json"
+}
+
+// decodeJSON decodes the JSON object of body into v
+func decodeJSON(body io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		if err == io.EOF {
+			return errEmptyBody
+		}
+		return err
+	}
+	if decoder.More() {
+		return errMultipleJSONValues
+	}
+	return nil
+}
+
+// decodeValues read the fields of req given in values
+func (req *summarizeRequest) decodeValues(values url.Values) error {
+	req.Text = values.Get("text")
+	req.Query = values.Get("query")
+	if v := values.Get("algorithm"); v != "" {
+		req.Algorithm = v
+	}
+	if v := values.Get("mode"); v != "" {
+		req.Mode = v
+	}
+	if v := values.Get("vector"); v != "" {
+		req.Vector = v
+	}
+	if v := values.Get("selection"); v != "" {
+		req.Selection = v
+	}
+	if err := req.filterRequest.decodeValues(values); err != nil {
+		return err
+	}
+
+	ints := []struct {
+		name  string
+		value *int
+	}{
+		{"maxLines", &req.MaxLines},
+		{"maxCharacters", &req.MaxCharacters},
+		{"maxBytes", &req.MaxBytes},
+		{"maxTokens", &req.MaxTokens},
+		{"maxIterations", &req.MaxIterations},
+	}
+	for _, field := range ints {
+		if values.Get(field.name) == "" {
+			continue
+		}
+		v, err := strconv.Atoi(values.Get(field.name))
+		if err != nil {
+			return &fieldError{field: field.name, err: err}
+		}
+		*field.value = v
+	}
+
+	floats := []struct {
+		name  string
+		value *float64
+	}{
+		{"maxRatio", &req.MaxRatio},
+		{"threshold", &req.Threshold},
+		{"tolerance", &req.Tolerance},
+		{"damping", &req.Damping},
+		{"lambda", &req.Lambda},
+		{"redundancyThreshold", &req.RedundancyThreshold},
+	}
+	for _, field := range floats {
+		if values.Get(field.name) == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(values.Get(field.name), 64)
+		if err != nil {
+			return &fieldError{field: field.name, err: err}
+		}
+		*field.value = v
+	}
+	return nil
+}
+
+// decodeValues read the fields of req given in values
+func (req *filterRequest) decodeValues(values url.Values) error {
+	if v := values.Get("baseForm"); v != "" {
+		baseForm, err := strconv.ParseBool(v)
+		if err != nil {
+			return &fieldError{field: "baseForm", isBool: true, err: err}
+		}
+		req.BaseForm = &baseForm
+	}
+	req.Dictionary = values.Get("dictionary")
+	req.PartsOfSpeech = values["partsOfSpeech"]
+	req.Stopwords = values["stopwords"]
+	return nil
+}
+
+var vectorModels = map[string]lexrankmmr.VectorModel{
+	"vocabulary": lexrankmmr.VocabularyVector,
+	"positional": lexrankmmr.PositionalVector,
+}
+
+// vectorModel returns lexrankmmr.VectorModel named by req.Vector
+func (req *summarizeRequest) vectorModel() (lexrankmmr.VectorModel, error) {
+	model, ok := vectorModels[req.Vector]
+	if !ok {
+		return 0, invalidValue("vector", `vector must be "vocabulary" or "positional"`)
+	}
+	return model, nil
+}
+
+var modes = map[string]lexrankmmr.Mode{
+	"discrete":   lexrankmmr.Discrete,
+	"continuous": lexrankmmr.Continuous,
+}
+
+// mode returns lexrankmmr.Mode named by req.Mode
+func (req *summarizeRequest) mode() (lexrankmmr.Mode, error) {
+	mode, ok := modes[req.Mode]
+	if !ok {
+		return 0, invalidValue("mode", `mode must be "discrete" or "continuous"`)
+	}
+	return mode, nil
+}
+
+var selections = map[string]lexrankmmr.Selection{
+	"knapsack": lexrankmmr.Knapsack,
+	"mmr":      lexrankmmr.BudgetedMMR,
+}
+
+// selection returns lexrankmmr.Selection named by req.Selection
+func (req *summarizeRequest) selection() (lexrankmmr.Selection, error) {
+	selection, ok := selections[req.Selection]
+	if !ok {
+		return 0, invalidValue("selection", `selection must be "knapsack" or "mmr"`)
+	}
+	return selection, nil
+}
+
+// algorithm returns lexrankmmr.Algorithm named by req.Algorithm
+func (req *summarizeRequest) algorithm() (lexrankmmr.Algorithm, error) {
+	names := make([]string, len(lexrankmmr.Algorithms))
+	for i, algorithm := range lexrankmmr.Algorithms {
+		if string(algorithm) == req.Algorithm {
+			return algorithm, nil
+		}
+		names[i] = strconv.Quote(string(algorithm))
+	}
+	return "", invalidValue("algorithm", "algorithm must be one of "+strings.Join(names, ", "))
+}
+
+// filterOptions returns options for the term filters given in the request.
+// partsOfSpeech and baseForm replace the server default and stopwords are added to it.
+func (req *filterRequest) filterOptions() []lexrankmmr.Option {
+	var options []lexrankmmr.Option
+	if req.BaseForm != nil {
+		options = append(options, lexrankmmr.BaseForm(*req.BaseForm))
+	}
+	if len(req.PartsOfSpeech) > 0 {
+		options = append(options, lexrankmmr.PartsOfSpeech(req.PartsOfSpeech...))
+	}
+	if len(req.Stopwords) > 0 {
+		options = append(options, lexrankmmr.Stopwords(req.Stopwords...))
+	}
+	return options
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+	"strings"
+)
+
+const (
+	apiPrefix   = "/v1"
+	adminPrefix = apiPrefix + "/admin/"
+)
+
+// openAPISpec is the OpenAPI document of the routes
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// route is an endpoint of the API
+type route struct {
+	// path is the path in openapi.json, and pattern is that of http.ServeMux
+	path    string
+	pattern string
+	methods []string
+	handler http.HandlerFunc
+}
+
+// routes returns the versioned endpoints of the API.
+// openapi.json must describe the same paths and methods.
+func (s *server) routes() []route {
+	return []route{
+		{apiPrefix + "/summarize", apiPrefix + "/summarize", []string{http.MethodPost}, s.summarizeHandler},
+		{apiPrefix + "/summarize/multi", apiPrefix + "/summarize/multi", []string{http.MethodPost}, s.multiHandler},
+		{apiPrefix + "/summarize/batch", apiPrefix + "/summarize/batch", []string{http.MethodPost}, s.batchHandler},
+		{apiPrefix + "/keywords", apiPrefix + "/keywords", []string{http.MethodPost}, s.keywordsHandler},
+		{jobsPath, jobsPath, []string{http.MethodPost}, s.jobsHandler},
+		{jobsPath + "/{id}", jobsPath + "/", []string{http.MethodGet, http.MethodDelete}, s.jobHandler},
+		{apiPrefix + "/admin/userdic", apiPrefix + "/admin/userdic", []string{http.MethodPut, http.MethodDelete}, s.userDicHandler},
+		{apiPrefix + "/openapi.json", apiPrefix + "/openapi.json", []string{http.MethodGet}, openAPIHandler},
+	}
+}
+
+// router returns the handler of all endpoints. POST / of earlier releases is
+// kept as an alias of POST /v1/summarize, and other paths are 404, as are the
+// admin endpoints when ADMIN_TOKEN is not set.
+func (s *server) router() http.Handler {
+	mux := http.NewServeMux()
+	for _, rt := range s.routes() {
+		handler := allowMethods(rt.methods, rt.handler)
+		if strings.HasPrefix(rt.path, adminPrefix) && s.adminToken == "" {
+			// disabled admin endpoints answer any method as unknown paths
+			handler = notFoundHandler
+		}
+		mux.HandleFunc(rt.pattern, handler)
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			notFoundHandler(w, r)
+			return
+		}
+		s.summarizeHandler(w, r)
+	})
+	return mux
+}
+
+// allowMethods answers the CORS preflight of h and rejects the other methods
+// not in methods
+func allowMethods(methods []string, h http.HandlerFunc) http.HandlerFunc {
+	allow := strings.Join(methods, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		for _, method := range methods {
+			if r.Method == method {
+				h(w, r)
+				return
+			}
+		}
+		setHeaders(w, r)
+		w.Header().Set("Access-Control-Allow-Methods", allow)
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Allow", allow)
+		writeError(w, &apiError{
+			status:  http.StatusMethodNotAllowed,
+			code:    codeMethodNotAllowed,
+			message: "method must be " + strings.Join(methods, " or "),
+		})
+	}
+}
+
+func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+	writeError(w, &apiError{
+		status:  http.StatusNotFound,
+		code:    codeNotFound,
+		message: "no endpoint at " + r.URL.Path,
+	})
+}
+
+// openAPIHandler returns openapi.json
+func openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	setHeaders(w, r)
+	w.Header().Set("Access-Control-Allow-Methods", "GET")
+	w.Write(openAPISpec)
+}